@@ -0,0 +1,131 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+const defaultSlabSize = 64
+
+// arena allocates nodes from large slabs and recycles freed nodes.
+// It's shared by all copies of a tree and is safe for concurrent use.
+//
+// Nodes from an arena count the references to them from parent nodes and
+// tree roots. A node is only referenced by more than one parent after Copy,
+// and copy-on-write drops the reference of the old node, so a node that has
+// been left behind by all trees is recycled when its count reaches zero.
+type arena[N numeric, T any] struct {
+	mu           sync.Mutex
+	slabSize     int
+	leaves       []leafNode[N, T]   // unused remainder of the leaf slab
+	branches     []branchNode[N, T] // unused remainder of the branch slab
+	freeLeaves   []*node[N, T]
+	freeBranches []*node[N, T]
+}
+
+// UseArena makes the tree allocate its nodes from slabs that hold slabSize
+// nodes each, rather than allocating each node separately. Nodes that are
+// removed from the tree by Delete, Sweep, and Clear are recycled, and so are
+// nodes that have been replaced by copy-on-write in all trees that shared
+// them. A slabSize of zero or less uses a default size.
+//
+// The arena is shared with all trees that are copied from this tree. Nodes
+// that the tree allocated before UseArena are not recycled.
+func (tr *RTreeGN[N, T]) UseArena(slabSize int) {
+	if slabSize < 1 {
+		slabSize = defaultSlabSize
+	}
+	if tr.arena == nil {
+		tr.arena = &arena[N, T]{}
+	}
+	tr.arena.mu.Lock()
+	tr.arena.slabSize = slabSize
+	tr.arena.mu.Unlock()
+}
+
+func (a *arena[N, T]) alloc(isleaf bool, icow uint64) *node[N, T] {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var n *node[N, T]
+	if isleaf {
+		if len(a.freeLeaves) > 0 {
+			n = a.freeLeaves[len(a.freeLeaves)-1]
+			a.freeLeaves[len(a.freeLeaves)-1] = nil
+			a.freeLeaves = a.freeLeaves[:len(a.freeLeaves)-1]
+		} else {
+			if len(a.leaves) == 0 {
+				a.leaves = make([]leafNode[N, T], a.slabSize)
+			}
+			n = (*node[N, T])(unsafe.Pointer(&a.leaves[0]))
+			a.leaves = a.leaves[1:]
+		}
+		n.kind = leaf
+	} else {
+		if len(a.freeBranches) > 0 {
+			n = a.freeBranches[len(a.freeBranches)-1]
+			a.freeBranches[len(a.freeBranches)-1] = nil
+			a.freeBranches = a.freeBranches[:len(a.freeBranches)-1]
+		} else {
+			if len(a.branches) == 0 {
+				a.branches = make([]branchNode[N, T], a.slabSize)
+			}
+			n = (*node[N, T])(unsafe.Pointer(&a.branches[0]))
+			a.branches = a.branches[1:]
+		}
+		n.kind = branch
+	}
+	n.icow = icow
+	n.pooled = true
+	n.refs = 1
+	return n
+}
+
+// release clears the node and adds it to the free list.
+func (a *arena[N, T]) release(n *node[N, T]) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if n.leaf() {
+		*(*leafNode[N, T])(unsafe.Pointer(n)) = leafNode[N, T]{}
+		a.freeLeaves = append(a.freeLeaves, n)
+	} else {
+		*(*branchNode[N, T])(unsafe.Pointer(n)) = branchNode[N, T]{}
+		a.freeBranches = append(a.freeBranches, n)
+	}
+}
+
+// free returns a node that has been removed from the tree to the arena.
+// Only nodes that were created by this tree since its last Copy are freed,
+// because all other nodes may still be in use by other trees.
+func (tr *RTreeGN[N, T]) free(n *node[N, T]) {
+	if tr.arena != nil && n.icow == tr.icow {
+		tr.arena.release(n)
+	}
+}
+
+// unref drops a reference to a node that this tree no longer uses. When it
+// was the last reference the node is recycled, along with its references to
+// its children.
+func (tr *RTreeGN[N, T]) unref(n *node[N, T]) {
+	if tr.arena == nil || !n.pooled || atomic.AddInt32(&n.refs, -1) != 0 {
+		return
+	}
+	if !n.leaf() {
+		for _, child := range n.children()[:n.count] {
+			tr.unref(child)
+		}
+	}
+	tr.arena.release(n)
+}
+
+// UseArena makes the tree allocate its nodes from slabs that hold slabSize
+// nodes each, rather than allocating each node separately. Nodes that are
+// removed from the tree are recycled.
+// A slabSize of zero or less uses a default size.
+func (tr *RTreeG[T]) UseArena(slabSize int) {
+	tr.base.UseArena(slabSize)
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestArena(t *testing.T) {
+	rand.Seed(seed)
+	N := 20_000
+	pts := make([]testPoint, N)
+	tr := new(RTreeG[testPoint])
+	tr.UseArena(16)
+	for i := range pts {
+		pts[i] = randTestPoint()
+		tr.Insert(pts[i].coord(), pts[i].coord(), pts[i])
+	}
+	// deleting everything frees all nodes
+	for _, pt := range pts {
+		tr.Delete(pt.coord(), pt.coord(), pt)
+	}
+	a := tr.base.arena
+	if tr.Len() != 0 || len(a.freeLeaves) == 0 || len(a.freeBranches) == 0 {
+		t.Fatalf("expected freed nodes")
+	}
+	nfree := len(a.freeLeaves)
+	for _, pt := range pts {
+		tr.Insert(pt.coord(), pt.coord(), pt)
+	}
+	if len(a.freeLeaves) >= nfree {
+		t.Fatalf("expected recycled nodes")
+	}
+	treePointsMatch(tr, pts)
+
+	// copies share the arena and may be used on different goroutines
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		tr2 := tr.Copy()
+		pts2 := append([]testPoint{}, pts...)
+		go testCopyOnWriteChild(4, &wg, tr2, pts2, 2)
+	}
+	wg.Wait()
+	treePointsMatch(tr, pts)
+	if err := rSane(tr); err != nil {
+		t.Fatal(err)
+	}
+	tr.Clear()
+	for i := range pts[:1000] {
+		tr.Insert(pts[i].coord(), pts[i].coord(), pts[i])
+	}
+	treePointsMatch(tr, pts[:1000])
+	if err := rSane(tr); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestArenaCopyOnWrite(t *testing.T) {
+	rand.Seed(seed)
+	N := 10_000
+	pts := make([]testPoint, N)
+	tr := new(RTreeG[testPoint])
+	tr.UseArena(0)
+	for i := range pts {
+		pts[i] = randTestPoint()
+		tr.Insert(pts[i].coord(), pts[i].coord(), pts[i])
+	}
+	var nodes []*node[float64, testPoint]
+	var walk func(n *node[float64, testPoint])
+	walk = func(n *node[float64, testPoint]) {
+		nodes = append(nodes, n)
+		if !n.leaf() {
+			for _, child := range n.children()[:n.count] {
+				walk(child)
+			}
+		}
+	}
+	walk(tr.base.root)
+	tr2 := tr.Copy()
+	// deleting from the tree copies all of the shared nodes
+	for _, pt := range pts {
+		tr.Delete(pt.coord(), pt.coord(), pt)
+	}
+	for _, n := range nodes {
+		if n.kind == none {
+			t.Fatalf("shared node was recycled")
+		}
+	}
+	treePointsMatch(tr2, pts)
+	// the old nodes are recycled once the copy no longer uses them
+	tr2.Clear()
+	for _, n := range nodes {
+		if n.kind != none {
+			t.Fatalf("expected recycled node")
+		}
+	}
+}
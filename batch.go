@@ -0,0 +1,187 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+// InsertBatch inserts many items into the tree.
+// The mins, maxs, and items slices must have the same lengths, and each
+// element from all slices must be associated.
+//
+// The items are first sorted along a Hilbert curve, and then groups of
+// neighboring items are pushed down the tree together, sharing the same path
+// from the root. This is faster than calling Insert for each item when the
+// items are spatially clustered, such as a GPS trace.
+func (tr *RTreeGN[N, T]) InsertBatch(mins, maxs [][2]N, items []T) {
+	if len(items) == 0 {
+		return
+	}
+	tr.mods++
+	keys := hilbertOrder(mins, maxs)
+	rects := make([]rect[N], len(items))
+	sorted := make([]T, len(items))
+	for i, key := range keys {
+		rects[i] = rect[N]{mins[key.idx], maxs[key.idx]}
+		sorted[i] = items[key.idx]
+	}
+	if tr.root == nil {
+		tr.init()
+		tr.root = tr.newNode(true)
+		tr.rect = rects[0]
+	}
+	var i int
+	for {
+		tr.cow(&tr.root)
+		i += tr.nodeInsertBatch(&tr.rect, tr.root, rects[i:], sorted[i:])
+		if i == len(rects) {
+			break
+		}
+		tr.splitRoot()
+		if orderBranches {
+			tr.root.sort()
+		}
+	}
+	tr.count += len(items)
+	if tr.watch != nil {
+		for i := range items {
+			tr.watch.notify(WatchEvent[N, T]{
+				Op: WatchInsert, NewMin: mins[i], NewMax: maxs[i],
+				NewData: items[i],
+			})
+		}
+	}
+}
+
+// nodeInsertBatch inserts items into the node until the node, or one of its
+// children, must be split and there's no room to do so. Returns the number of
+// items inserted. The nr rect is expanded to include the inserted items.
+func (tr *RTreeGN[N, T]) nodeInsertBatch(nr *rect[N], n *node[N, T],
+	rects []rect[N], data []T,
+) (inserted int) {
+	if n.leaf() {
+		items := n.items()
+		for ; inserted < len(rects) && n.count < maxEntries; inserted++ {
+			ir := &rects[inserted]
+			index := int(n.count)
+			if orderLeaves {
+				index = n.rsearch(ir.min[0])
+				copy(n.rects[index+1:int(n.count)+1], n.rects[index:int(n.count)])
+				copy(items[index+1:int(n.count)+1], items[index:int(n.count)])
+				if n.exps != nil {
+					copy(n.exps[index+1:int(n.count)+1],
+						n.exps[index:int(n.count)])
+				}
+			}
+			n.rects[index] = *ir
+			items[index] = data[inserted]
+			n.setexp(index, 0)
+			n.count++
+			nr.expand(ir)
+		}
+		return inserted
+	}
+	children := n.children()
+	for inserted < len(rects) {
+		index := n.chooseSubtree(&rects[inserted])
+		// Group the following items that go into the same child. The items
+		// are in spatial order, so they stay with the child while they are
+		// near it, which is while they don't double the area of the child
+		// and the items before them. A leaf child takes no more items than
+		// it has room for.
+		child := children[index]
+		limit := len(rects)
+		if child.leaf() && inserted+maxEntries-int(child.count) < limit {
+			limit = inserted + maxEntries - int(child.count)
+		}
+		group := n.rects[index]
+		group.expand(&rects[inserted])
+		end := inserted + 1
+		for ; end < limit; end++ {
+			ir := &rects[end]
+			if !group.contains(ir) {
+				if group.unionedArea(ir) > group.area()*2 {
+					break
+				}
+				group.expand(ir)
+			}
+		}
+		tr.cow(&children[index])
+		inserted += tr.nodeInsertBatch(&n.rects[index], children[index],
+			rects[inserted:end], data[inserted:end])
+		nr.expand(&n.rects[index])
+		if orderBranches {
+			index = n.orderToLeft(index)
+		}
+		if inserted < end {
+			// The child is full
+			if n.count == maxEntries {
+				break
+			}
+			tr.splitChild(n, index)
+		}
+	}
+	return inserted
+}
+
+// hilbertOrder returns the sort keys for the rects, ordered by the Hilbert
+// curve value of their centers.
+func hilbertOrder[N numeric](mins, maxs [][2]N) []lkey[uint32] {
+	bmin, bmax := mins[0], maxs[0]
+	for i := range mins {
+		for j := 0; j < 2; j++ {
+			bmin[j] = fmin(bmin[j], mins[i][j])
+			bmax[j] = fmax(bmax[j], maxs[i][j])
+		}
+	}
+	var scale [2]float64
+	for j := 0; j < 2; j++ {
+		if bmax[j] > bmin[j] {
+			scale[j] = 0xFFFF / (float64(bmax[j]) - float64(bmin[j]))
+		}
+	}
+	keys := make([]lkey[uint32], len(mins))
+	for i := range keys {
+		var xy [2]uint32
+		for j := 0; j < 2; j++ {
+			c := (float64(mins[i][j]) + float64(maxs[i][j])) / 2
+			xy[j] = uint32((c - float64(bmin[j])) * scale[j])
+		}
+		keys[i] = lkey[uint32]{c: [2]uint32{hilbert(xy[0], xy[1])}, idx: i}
+	}
+	ksort(keys, 0)
+	return keys
+}
+
+// hilbertCurve is the Hilbert curve as a state machine that reads two bits
+// of x and y at a time. For a state and the bits, the low four bits are the
+// curve value and the high bits are the next state.
+var hilbertCurve = [64]uint8{
+	0x00, 0x13, 0x24, 0x05, 0x21, 0x22, 0x37, 0x06,
+	0x3e, 0x3d, 0x28, 0x09, 0x0f, 0x1c, 0x3b, 0x0a,
+	0x1a, 0x2b, 0x0c, 0x1f, 0x19, 0x38, 0x2d, 0x2e,
+	0x16, 0x27, 0x32, 0x31, 0x15, 0x34, 0x03, 0x10,
+	0x20, 0x01, 0x1e, 0x2f, 0x33, 0x02, 0x1d, 0x3c,
+	0x04, 0x17, 0x08, 0x1b, 0x25, 0x26, 0x29, 0x2a,
+	0x3a, 0x39, 0x36, 0x35, 0x0b, 0x18, 0x07, 0x14,
+	0x2c, 0x0d, 0x12, 0x23, 0x3f, 0x0e, 0x11, 0x30,
+}
+
+// hilbert returns the Hilbert curve value for a 16-bit x/y position.
+func hilbert(x, y uint32) uint32 {
+	var d, state uint32
+	for i := 14; i >= 0; i -= 2 {
+		v := uint32(hilbertCurve[state|(x>>i&3)<<2|y>>i&3])
+		d = d<<4 | v&15
+		state = v &^ 15
+	}
+	return d
+}
+
+// InsertBatch inserts many items into the tree.
+// The mins, maxs, and items slices must have the same lengths, and each
+// element from all slices must be associated.
+// This is faster than calling Insert for each item when the items are
+// spatially clustered.
+func (tr *RTreeG[T]) InsertBatch(mins, maxs [][2]float64, items []T) {
+	tr.base.InsertBatch(mins, maxs, items)
+}
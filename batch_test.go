@@ -0,0 +1,109 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// randTrace returns a random walk of points, like a GPS trace.
+func randTrace(n int) (mins, maxs [][2]float64) {
+	mins = make([][2]float64, n)
+	pt := [2]float64{rand.Float64()*360 - 180, rand.Float64()*180 - 90}
+	for i := range mins {
+		pt[0] += rand.Float64()*0.01 - 0.005
+		pt[1] += rand.Float64()*0.01 - 0.005
+		mins[i] = pt
+	}
+	return mins, mins
+}
+
+func TestInsertBatch(t *testing.T) {
+	rand.Seed(seed)
+	var tr RTreeG[int]
+	var all [][2]float64
+	var n int
+	for i := 0; i < 200; i++ {
+		var mins, maxs [][2]float64
+		if i%2 == 0 {
+			mins, maxs = randTrace(rand.Intn(2000))
+		} else {
+			for j := rand.Intn(500); j > 0; j-- {
+				r := randRect('m')
+				mins, maxs = append(mins, r.min), append(maxs, r.max)
+			}
+		}
+		items := make([]int, len(mins))
+		for j := range items {
+			items[j] = n
+			n++
+		}
+		all = append(all, mins...)
+		tr.InsertBatch(mins, maxs, items)
+		if tr.Len() != n {
+			t.Fatalf("expected %d, got %d", n, tr.Len())
+		}
+	}
+	if err := rSane(&tr); err != nil {
+		t.Fatal(err)
+	}
+	seen := make([]bool, n)
+	tr.Scan(func(min, max [2]float64, i int) bool {
+		seen[i] = true
+		return true
+	})
+	for i := range seen {
+		if !seen[i] {
+			t.Fatalf("missing %d", i)
+		}
+	}
+	// the first trace points can be deleted
+	for i := 0; i < 100; i++ {
+		tr.Delete(all[i], all[i], i)
+	}
+	if err := rSane(&tr); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// benchTraces returns a tree of random points and GPS traces to insert
+// into it.
+func benchTraces() (tr *RTreeG[int], mins [][][2]float64) {
+	rand.Seed(seed)
+	tr = new(RTreeG[int])
+	for i := 0; i < 100_000; i++ {
+		r := randRect('p')
+		tr.Insert(r.min, r.max, i)
+	}
+	mins = make([][][2]float64, 50)
+	for i := range mins {
+		mins[i], _ = randTrace(2000)
+	}
+	return tr, mins
+}
+
+func BenchmarkInsertBatch(b *testing.B) {
+	base, mins := benchTraces()
+	items := make([]int, len(mins[0]))
+	b.Run("insert", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tr := base.Copy()
+			for _, mins := range mins {
+				for j := range mins {
+					tr.Insert(mins[j], mins[j], items[j])
+				}
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tr := base.Copy()
+			for _, mins := range mins {
+				tr.InsertBatch(mins, mins, items)
+			}
+		}
+	})
+}
@@ -0,0 +1,289 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Package cluster groups points into clusters, either zoom-dependent clusters
+// for rendering on web maps, in the style of supercluster, or density-based
+// clusters with DBSCAN.
+package cluster
+
+import (
+	"errors"
+	"math"
+
+	"github.com/tidwall/rtree"
+)
+
+var (
+	// ErrNotFound is returned when there is no cluster with the provided id.
+	ErrNotFound = errors.New("cluster not found")
+	// ErrInvalidZoom is returned by New when the min zoom is negative or
+	// greater than the max zoom.
+	ErrInvalidZoom = errors.New("invalid zoom range")
+	// ErrInvalidOptions is returned by New when the extent is not positive,
+	// the radius is negative, or the min points is less than one.
+	ErrInvalidOptions = errors.New("invalid options")
+)
+
+// Options for building an Index.
+// All fields are used as they are, so start from DefaultOptions to change
+// only some of them.
+type Options struct {
+	MinZoom   int     // lowest zoom with clusters, default 0
+	MaxZoom   int     // highest zoom with clusters, default 16
+	Radius    float64 // cluster radius in pixels, default 40
+	Extent    float64 // tile extent that the radius is relative to, default 512
+	MinPoints int     // minimum points to form a cluster, default 2
+}
+
+// DefaultOptions are used when nil options are passed to New.
+var DefaultOptions = Options{
+	MinZoom:   0,
+	MaxZoom:   16,
+	Radius:    40,
+	Extent:    512,
+	MinPoints: 2,
+}
+
+// Cluster is either a cluster of points or a single point.
+type Cluster[T any] struct {
+	// ID is the cluster id, or the index of the point that was passed to
+	// New when Count is one.
+	ID int
+	// Point is the longitude and latitude of the point, or the weighted
+	// center of all points in the cluster.
+	Point [2]float64
+	// Count is the number of points.
+	Count int
+	// Data is the data of the point, when Count is one.
+	Data T
+}
+
+// node is a point or cluster in projected coordinates, where the world is
+// 0,0 to 1,1 in Web Mercator.
+type node struct {
+	x, y  float64
+	count int
+}
+
+// Index is a set of points that is clustered at each zoom level.
+// Each zoom level has its own R-tree of the points and clusters, using
+// projected coordinates.
+type Index[T any] struct {
+	opts     Options
+	data     []T
+	npoints  int
+	nodes    []node  // points first, then clusters, by id
+	children [][]int // cluster children ids, by id minus npoints
+	trees    []rtree.RTreeG[int]
+}
+
+// New returns an Index of the points, which are longitude and latitude
+// degrees, in that order. The data slice must have the same length as the
+// points, and the ids of the points are their index in the slices.
+// Returns ErrInvalidZoom or ErrInvalidOptions when the options are not
+// valid.
+func New[T any](opts *Options, points [][2]float64, data []T,
+) (*Index[T], error) {
+	o := DefaultOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.MinZoom < 0 || o.MinZoom > o.MaxZoom {
+		return nil, ErrInvalidZoom
+	}
+	if !(o.Extent > 0) || !(o.Radius >= 0) || o.MinPoints < 1 {
+		return nil, ErrInvalidOptions
+	}
+	idx := &Index[T]{
+		opts:    o,
+		data:    data,
+		npoints: len(points),
+		nodes:   make([]node, len(points)),
+		trees:   make([]rtree.RTreeG[int], o.MaxZoom-o.MinZoom+2),
+	}
+	ids := make([]int, len(points))
+	for i, pt := range points {
+		idx.nodes[i] = node{x: lonX(pt[0]), y: latY(pt[1]), count: 1}
+		ids[i] = i
+	}
+	idx.load(o.MaxZoom+1, ids)
+
+	// Cluster each zoom level from the one above it.
+	marks := make([]int, len(points))
+	for i := range marks {
+		marks[i] = -1
+	}
+	for z := o.MaxZoom; z >= o.MinZoom; z-- {
+		ids, marks = idx.cluster(ids, marks, z)
+		idx.load(z, ids)
+	}
+	return idx, nil
+}
+
+// load the ids into the tree for the zoom.
+func (idx *Index[T]) load(z int, ids []int) {
+	pts := make([][2]float64, len(ids))
+	for i, id := range ids {
+		pts[i] = [2]float64{idx.nodes[id].x, idx.nodes[id].y}
+	}
+	idx.trees[z-idx.opts.MinZoom].Load(pts, pts, ids)
+}
+
+// cluster the ids of the zoom above z, and returns the ids for zoom z.
+// The marks are the zoom where an id was last visited, by id.
+func (idx *Index[T]) cluster(ids []int, marks []int, z int) ([]int, []int) {
+	tr := &idx.trees[z+1-idx.opts.MinZoom]
+	r := idx.opts.Radius / (idx.opts.Extent * math.Pow(2, float64(z)))
+	var next, neighbors []int
+	for _, id := range ids {
+		if marks[id] == z {
+			continue
+		}
+		marks[id] = z
+		p := idx.nodes[id]
+		neighbors = neighbors[:0]
+		count := p.count
+		pt := [2]float64{p.x, p.y}
+		tr.SearchRadius(pt, r, false, func(_, _ [2]float64, id2 int) bool {
+			if marks[id2] != z {
+				neighbors = append(neighbors, id2)
+				count += idx.nodes[id2].count
+			}
+			return true
+		})
+		if len(neighbors) == 0 || count < idx.opts.MinPoints {
+			// not enough points to cluster, so everything passes through
+			next = append(next, id)
+			if len(neighbors) > 0 {
+				for _, id2 := range neighbors {
+					marks[id2] = z
+				}
+				next = append(next, neighbors...)
+			}
+			continue
+		}
+		// the cluster is at the weighted center of its children
+		wx, wy := p.x*float64(p.count), p.y*float64(p.count)
+		for _, id2 := range neighbors {
+			marks[id2] = z
+			n := idx.nodes[id2]
+			wx += n.x * float64(n.count)
+			wy += n.y * float64(n.count)
+		}
+		cid := len(idx.nodes)
+		idx.nodes = append(idx.nodes, node{
+			x: wx / float64(count), y: wy / float64(count), count: count,
+		})
+		idx.children = append(idx.children,
+			append([]int{id}, neighbors...))
+		marks = append(marks, z)
+		next = append(next, cid)
+	}
+	return next, marks
+}
+
+// get returns the point or cluster for the id.
+func (idx *Index[T]) get(id int) Cluster[T] {
+	n := idx.nodes[id]
+	c := Cluster[T]{ID: id, Point: [2]float64{xLon(n.x), yLat(n.y)},
+		Count: n.count}
+	if id < idx.npoints {
+		c.Data = idx.data[id]
+	}
+	return c
+}
+
+// GetClusters returns the clusters and points in the longitude and latitude
+// rect at the zoom. When the min longitude is greater than the max longitude,
+// the rect crosses the antimeridian.
+func (idx *Index[T]) GetClusters(min, max [2]float64, zoom int) []Cluster[T] {
+	if zoom < idx.opts.MinZoom {
+		zoom = idx.opts.MinZoom
+	} else if zoom > idx.opts.MaxZoom+1 {
+		zoom = idx.opts.MaxZoom + 1
+	}
+	if min[0] > max[0] {
+		west := idx.GetClusters(min, [2]float64{180, max[1]}, zoom)
+		east := idx.GetClusters([2]float64{-180, min[1]}, max, zoom)
+		return append(west, east...)
+	}
+	var clusters []Cluster[T]
+	tr := &idx.trees[zoom-idx.opts.MinZoom]
+	tr.Search(
+		[2]float64{lonX(min[0]), latY(max[1])},
+		[2]float64{lonX(max[0]), latY(min[1])},
+		func(_, _ [2]float64, id int) bool {
+			clusters = append(clusters, idx.get(id))
+			return true
+		},
+	)
+	return clusters
+}
+
+// GetChildren returns the clusters and points that the cluster was made
+// from, which are at the next zoom.
+func (idx *Index[T]) GetChildren(id int) ([]Cluster[T], error) {
+	if id < idx.npoints || id >= len(idx.nodes) {
+		return nil, ErrNotFound
+	}
+	ids := idx.children[id-idx.npoints]
+	children := make([]Cluster[T], len(ids))
+	for i, id := range ids {
+		children[i] = idx.get(id)
+	}
+	return children, nil
+}
+
+// GetLeaves returns the points of the cluster, skipping the first offset
+// points and returning up to limit points. A limit of zero or less returns
+// all points.
+func (idx *Index[T]) GetLeaves(id, limit, offset int) ([]Cluster[T], error) {
+	if id < idx.npoints || id >= len(idx.nodes) {
+		return nil, ErrNotFound
+	}
+	if limit <= 0 {
+		limit = idx.nodes[id].count
+	}
+	var leaves []Cluster[T]
+	idx.leaves(id, limit, &offset, &leaves)
+	return leaves, nil
+}
+
+func (idx *Index[T]) leaves(id, limit int, skip *int, leaves *[]Cluster[T]) {
+	for _, child := range idx.children[id-idx.npoints] {
+		if len(*leaves) == limit {
+			return
+		}
+		if count := idx.nodes[child].count; *skip >= count {
+			// skip the whole cluster
+			*skip -= count
+		} else if child < idx.npoints {
+			*leaves = append(*leaves, idx.get(child))
+		} else {
+			idx.leaves(child, limit, skip, leaves)
+		}
+	}
+}
+
+// lonX projects the longitude to the range 0 to 1.
+func lonX(lon float64) float64 {
+	return lon/360 + 0.5
+}
+
+// latY projects the latitude to Web Mercator in the range 0 to 1, where 0 is
+// the north.
+func latY(lat float64) float64 {
+	sin := math.Sin(lat * math.Pi / 180)
+	y := 0.5 - 0.25*math.Log((1+sin)/(1-sin))/math.Pi
+	return math.Max(0, math.Min(1, y))
+}
+
+func xLon(x float64) float64 {
+	return (x - 0.5) * 360
+}
+
+func yLat(y float64) float64 {
+	y2 := (180 - y*360) * math.Pi / 180
+	return 360*math.Atan(math.Exp(y2))/math.Pi - 90
+}
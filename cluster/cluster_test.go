@@ -0,0 +1,168 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package cluster
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+var world = [2][2]float64{{-180, -85}, {180, 85}}
+
+func TestCluster(t *testing.T) {
+	// two tight groups, far apart
+	var pts [][2]float64
+	var data []string
+	for i := 0; i < 10; i++ {
+		pts = append(pts, [2]float64{10 + float64(i)*0.001, 10})
+		data = append(data, "a")
+	}
+	for i := 0; i < 5; i++ {
+		pts = append(pts, [2]float64{-100, -20 + float64(i)*0.001})
+		data = append(data, "b")
+	}
+	idx, err := New(nil, pts, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clusters := idx.GetClusters(world[0], world[1], 0)
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %d", len(clusters))
+	}
+	for _, c := range clusters {
+		if (c.Count != 10 || math.Abs(c.Point[0]-10.0045) > 1e-6 ||
+			math.Abs(c.Point[1]-10) > 1e-6) &&
+			(c.Count != 5 || math.Abs(c.Point[0]+100) > 1e-6) {
+			t.Fatalf("unexpected cluster %+v", c)
+		}
+		leaves, err := idx.GetLeaves(c.ID, 0, 0)
+		if err != nil || len(leaves) != c.Count {
+			t.Fatalf("expected %d leaves, got %d", c.Count, len(leaves))
+		}
+		for _, leaf := range leaves {
+			if leaf.Count != 1 || leaf.Data != data[leaf.ID] ||
+				math.Abs(leaf.Point[0]-pts[leaf.ID][0]) > 1e-9 ||
+				math.Abs(leaf.Point[1]-pts[leaf.ID][1]) > 1e-9 {
+				t.Fatalf("unexpected leaf %+v", leaf)
+			}
+		}
+	}
+	// all points at the highest zoom
+	if n := len(idx.GetClusters(world[0], world[1], 20)); n != len(pts) {
+		t.Fatalf("expected %d points, got %d", len(pts), n)
+	}
+	// the antimeridian
+	if n := len(idx.GetClusters([2]float64{170, -85}, [2]float64{-90, 85},
+		0)); n != 1 {
+		t.Fatalf("expected 1 cluster, got %d", n)
+	}
+	if _, err := idx.GetChildren(0); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := idx.GetLeaves(1000, 0, 0); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestClusterOptions(t *testing.T) {
+	pts := [][2]float64{{0, 0}, {50, 50}, {-100, 20}}
+	data := []int{0, 1, 2}
+	// a max zoom of zero is kept
+	opts := DefaultOptions
+	opts.MaxZoom = 0
+	idx, err := New(&opts, pts, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.trees) != 2 {
+		t.Fatalf("expected 2 trees, got %d", len(idx.trees))
+	}
+	if n := len(idx.GetClusters(world[0], world[1], 0)); n != 3 {
+		t.Fatalf("expected 3 clusters, got %d", n)
+	}
+	invalid := []struct {
+		opts Options
+		err  error
+	}{
+		{Options{MinZoom: 5, MaxZoom: 4, Radius: 40, Extent: 512,
+			MinPoints: 2}, ErrInvalidZoom},
+		{Options{MinZoom: -1, MaxZoom: 4, Radius: 40, Extent: 512,
+			MinPoints: 2}, ErrInvalidZoom},
+		// only some fields are set, so the extent is zero
+		{Options{MaxZoom: 16, Radius: 40}, ErrInvalidOptions},
+		{Options{MaxZoom: 16, Radius: -1, Extent: 512,
+			MinPoints: 2}, ErrInvalidOptions},
+		{Options{MaxZoom: 16, Radius: math.NaN(), Extent: 512,
+			MinPoints: 2}, ErrInvalidOptions},
+		{Options{MaxZoom: 16, Radius: 40, Extent: 512}, ErrInvalidOptions},
+	}
+	for _, c := range invalid {
+		if _, err := New(&c.opts, pts, data); err != c.err {
+			t.Fatalf("%+v: expected %v, got %v", c.opts, c.err, err)
+		}
+	}
+}
+
+func TestClusterRandom(t *testing.T) {
+	seed := rand.Int63()
+	t.Logf("seed: %d", seed)
+	rand.Seed(seed)
+	N := 20_000
+	pts := make([][2]float64, N)
+	data := make([]int, N)
+	for i := range pts {
+		pts[i] = [2]float64{rand.Float64()*360 - 180, rand.Float64()*170 - 85}
+		data[i] = i
+	}
+	idx, err := New(&Options{MaxZoom: 10, Radius: 60, Extent: 256,
+		MinPoints: 3}, pts, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for z := 0; z <= 11; z++ {
+		var count int
+		for _, c := range idx.GetClusters(world[0], world[1], z) {
+			count += c.Count
+			if c.Count == 1 {
+				continue
+			}
+			if c.Count < 3 {
+				t.Fatalf("cluster with %d points", c.Count)
+			}
+			children, err := idx.GetChildren(c.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ccount int
+			for _, child := range children {
+				ccount += child.Count
+			}
+			if ccount != c.Count {
+				t.Fatalf("expected %d child points, got %d", c.Count, ccount)
+			}
+			// paging the leaves returns each point once
+			seen := make(map[int]bool)
+			for offset := 0; offset < c.Count; offset += 2 {
+				leaves, _ := idx.GetLeaves(c.ID, 2, offset)
+				if len(leaves) != 2 && offset+len(leaves) != c.Count {
+					t.Fatalf("expected a full page, got %d", len(leaves))
+				}
+				for _, leaf := range leaves {
+					if seen[leaf.ID] {
+						t.Fatalf("duplicate leaf %d", leaf.ID)
+					}
+					seen[leaf.ID] = true
+				}
+			}
+			if len(seen) != c.Count {
+				t.Fatalf("expected %d leaves, got %d", c.Count, len(seen))
+			}
+		}
+		if count != N {
+			t.Fatalf("zoom %d: expected %d points, got %d", z, N, count)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package cluster
+
+import "github.com/tidwall/rtree"
+
+type numeric interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Noise is the DBSCAN label for points that are not in a cluster.
+const Noise = -1
+
+const unvisited = -2
+
+// DBSCAN clusters the items in the tree with the DBSCAN algorithm, and
+// returns the number of clusters. The items are points, or rects that are
+// measured by their centers. A point is a core point when there are at least
+// minPts points, including itself, within eps of it, and clusters are made of
+// the core points that are within eps of each other plus the points within
+// eps of those.
+//
+// The fn function is called for each item, in the order of Scan, with the
+// cluster label, which starts at zero, or Noise. Each item is its own point,
+// so items with the same rect are counted separately toward minPts.
+//
+// The points are labeled by their position in the order of Scan. Neighbors
+// are found with SearchRadius on an index of those positions, which skips the
+// nodes that are further than eps.
+func DBSCAN[N numeric, T any](tr *rtree.RTreeGN[N, T], eps N,
+	minPts int, fn func(min, max [2]N, data T, label int),
+) int {
+	mins := make([][2]N, 0, tr.Len())
+	maxs := make([][2]N, 0, tr.Len())
+	slots := make([]int, 0, tr.Len())
+	tr.Scan(func(min, max [2]N, _ T) bool {
+		slots = append(slots, len(slots))
+		mins = append(mins, min)
+		maxs = append(maxs, max)
+		return true
+	})
+	var index rtree.RTreeGN[N, int]
+	index.Load(mins, maxs, slots)
+	labels := make([]int, len(mins))
+	for i := range labels {
+		labels[i] = unvisited
+	}
+
+	// The neighbors and the seed queue are reused for every point.
+	var neighbors, seeds []int
+	region := func(i int) []int {
+		neighbors = neighbors[:0]
+		center := [2]N{(mins[i][0] + maxs[i][0]) / 2,
+			(mins[i][1] + maxs[i][1]) / 2}
+		index.SearchRadius(center, eps, true,
+			func(_, _ [2]N, slot int) bool {
+				neighbors = append(neighbors, slot)
+				return true
+			},
+		)
+		return neighbors
+	}
+	var nclusters int
+	for i := range labels {
+		if labels[i] != unvisited {
+			continue
+		}
+		if len(region(i)) < minPts {
+			labels[i] = Noise
+			continue
+		}
+		label := nclusters
+		nclusters++
+		labels[i] = label
+		seeds = append(seeds[:0], neighbors...)
+		for j := 0; j < len(seeds); j++ {
+			q := seeds[j]
+			if labels[q] == Noise {
+				// a border point
+				labels[q] = label
+			}
+			if labels[q] != unvisited {
+				continue
+			}
+			labels[q] = label
+			if len(region(q)) >= minPts {
+				seeds = append(seeds, neighbors...)
+			}
+		}
+	}
+	var slot int
+	tr.Scan(func(min, max [2]N, data T) bool {
+		fn(min, max, data, labels[slot])
+		slot++
+		return true
+	})
+	return nclusters
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package cluster
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/tidwall/rtree"
+)
+
+func TestDBSCAN(t *testing.T) {
+	seed := rand.Int63()
+	t.Logf("seed: %d", seed)
+	rand.Seed(seed)
+	var tr rtree.RTreeGN[float64, int]
+	var pts [][2]float64
+	// three blobs and some noise
+	for _, c := range [][2]float64{{100, 100}, {300, 150}, {200, 400}} {
+		for i := 0; i < 500; i++ {
+			pts = append(pts, [2]float64{
+				c[0] + rand.NormFloat64()*15, c[1] + rand.NormFloat64()*15,
+			})
+		}
+	}
+	for i := 0; i < 300; i++ {
+		pts = append(pts, [2]float64{rand.Float64() * 500,
+			rand.Float64() * 500})
+	}
+	for i, pt := range pts {
+		tr.Insert(pt, pt, i)
+	}
+	eps, minPts := 8.0, 5
+
+	// brute force the core points
+	within := func(a, b [2]float64) bool {
+		dx, dy := a[0]-b[0], a[1]-b[1]
+		return dx*dx+dy*dy <= eps*eps
+	}
+	core := make([]bool, len(pts))
+	for i := range pts {
+		var n int
+		for j := range pts {
+			if within(pts[i], pts[j]) {
+				n++
+			}
+		}
+		core[i] = n >= minPts
+	}
+
+	labels := make([]int, len(pts))
+	var calls int
+	n := DBSCAN(&tr, eps, minPts,
+		func(min, max [2]float64, i int, label int) {
+			if min != pts[i] || max != pts[i] {
+				t.Fatalf("unexpected rect")
+			}
+			labels[i] = label
+			calls++
+		},
+	)
+	if calls != len(pts) {
+		t.Fatalf("expected %d calls, got %d", len(pts), calls)
+	}
+	if n < 3 {
+		t.Fatalf("expected at least 3 clusters, got %d", n)
+	}
+	for i := range pts {
+		if labels[i] < Noise || labels[i] >= n {
+			t.Fatalf("invalid label %d", labels[i])
+		}
+		// core points within eps share a cluster, and everything else is in
+		// the cluster of a core neighbor, or is noise
+		var found bool
+		for j := range pts {
+			if !core[j] || !within(pts[i], pts[j]) {
+				continue
+			}
+			if core[i] && labels[i] != labels[j] {
+				t.Fatalf("core points %d and %d are in different clusters",
+					i, j)
+			}
+			found = found || labels[i] == labels[j]
+		}
+		if found != (labels[i] != Noise) {
+			t.Fatalf("point %d has label %d", i, labels[i])
+		}
+	}
+}
+
+func TestDBSCANDuplicates(t *testing.T) {
+	var tr rtree.RTreeGN[int, int]
+	// a cluster of three points, one of which is in the tree twice, and a
+	// point that is in twice, which is noise because the two copies are only
+	// two points
+	for _, pt := range [][2]int{{0, 0}, {1, 0}, {0, 1}, {0, 1}, {50, 50},
+		{50, 50}} {
+		tr.Insert(pt, pt, 0)
+	}
+	var calls int
+	labels := make(map[[2]int]int)
+	n := DBSCAN(&tr, 2, 3, func(min, max [2]int, _ int, label int) {
+		if prev, ok := labels[min]; ok && prev != label {
+			t.Fatalf("duplicate %v has labels %d and %d", min, prev, label)
+		}
+		labels[min] = label
+		calls++
+	})
+	if calls != tr.Len() {
+		t.Fatalf("expected %d calls, got %d", tr.Len(), calls)
+	}
+	if n != 1 || labels[[2]int{0, 1}] != 0 || labels[[2]int{50, 50}] != Noise {
+		t.Fatalf("unexpected labels %v", labels)
+	}
+}
+
+func TestDBSCANAnyData(t *testing.T) {
+	// the data does not need to be comparable
+	var tr rtree.RTreeGN[float64, []int]
+	for i, pt := range [][2]float64{{0, 0}, {1, 1}, {10, 10}} {
+		tr.Insert(pt, pt, []int{i})
+	}
+	labels := make([]int, tr.Len())
+	n := DBSCAN(&tr, 2, 2, func(_, _ [2]float64, data []int, label int) {
+		labels[data[0]] = label
+	})
+	if n != 1 || labels[0] != 0 || labels[1] != 0 || labels[2] != Noise {
+		t.Fatalf("unexpected labels %v", labels)
+	}
+}
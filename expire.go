@@ -0,0 +1,233 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import "time"
+
+// Expirations are stored per node in the optional `node[N,T].exps` array,
+// which is parallel to `node[N,T].rects`. For a leaf, each entry is the
+// expiration of the item. For a branch, each entry is a lower bound of all
+// expirations in the child subtree. Zero means never. The array is only
+// allocated for nodes that have seen an expiring item, so trees that don't
+// use expirations pay for one nil pointer per node.
+
+// expmin returns the earliest of two expirations.
+func expmin(a, b int64) int64 {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+func (n *node[N, T]) exp(i int) int64 {
+	if n.exps == nil {
+		return 0
+	}
+	return n.exps[i]
+}
+
+func (n *node[N, T]) setexp(i int, exp int64) {
+	if n.exps == nil {
+		if exp == 0 {
+			return
+		}
+		n.exps = new([maxEntries]int64)
+	}
+	n.exps[i] = exp
+}
+
+// minexp returns the earliest expiration of all entries in the node.
+func (n *node[N, T]) minexp() int64 {
+	if n.exps == nil {
+		return 0
+	}
+	var exp int64
+	for i := 0; i < int(n.count); i++ {
+		exp = expmin(exp, n.exps[i])
+	}
+	return exp
+}
+
+func expired(exp, now int64) bool {
+	return exp != 0 && exp <= now
+}
+
+// InsertExpires inserts data into the tree that expires at the provided time.
+// Expired items are removed by Sweep. A zero time means that the item never
+// expires.
+func (tr *RTreeGN[N, T]) InsertExpires(min, max [2]N, data T,
+	expires time.Time,
+) {
+	var exp int64
+	if !expires.IsZero() {
+		exp = expires.UnixNano()
+	}
+	tr.insert(min, max, data, exp, velocity[N]{})
+	if tr.watch != nil {
+		tr.watch.notify(WatchEvent[N, T]{
+			Op: WatchInsert, NewMin: min, NewMax: max, NewData: data,
+		})
+	}
+}
+
+// Sweep removes all items that have expired at the provided time, and
+// returns the number of items removed. Subtrees that do not contain expired
+// items are skipped.
+func (tr *RTreeGN[N, T]) Sweep(now time.Time) int {
+	if tr.root == nil || !expired(tr.root.minexp(), now.UnixNano()) {
+		return 0
+	}
+	var evs []WatchEvent[N, T]
+	var onremove func(r *rect[N], data T)
+	if tr.watch != nil {
+		onremove = func(r *rect[N], data T) {
+			evs = append(evs, WatchEvent[N, T]{
+				Op: WatchDelete, OldMin: r.min, OldMax: r.max, OldData: data,
+			})
+		}
+	}
+	tr.mods++
+	tr.cow(&tr.root)
+	removed := tr.nodeSweep(tr.root, now.UnixNano(), onremove)
+	tr.count -= removed
+	if tr.count == 0 {
+		tr.free(tr.root)
+		tr.root = nil
+		tr.rect = rect[N]{}
+	} else {
+		for !tr.root.leaf() && tr.root.count == 1 {
+			old := tr.root
+			tr.root = tr.root.children()[0]
+			tr.free(old)
+		}
+		tr.rect = tr.root.rect()
+	}
+	for _, ev := range evs {
+		tr.watch.notify(ev)
+	}
+	return removed
+}
+
+func (tr *RTreeGN[N, T]) nodeSweep(n *node[N, T], now int64,
+	onremove func(r *rect[N], data T),
+) (removed int) {
+	var j int
+	if n.leaf() {
+		if n.exps == nil {
+			return 0
+		}
+		items := n.items()
+		for i := 0; i < int(n.count); i++ {
+			if expired(n.exps[i], now) {
+				if onremove != nil {
+					onremove(&n.rects[i], items[i])
+				}
+				removed++
+				continue
+			}
+			n.rects[j] = n.rects[i]
+			items[j] = items[i]
+			n.exps[j] = n.exps[i]
+			j++
+		}
+		for i := j; i < int(n.count); i++ {
+			items[i] = tr.empty
+			n.exps[i] = 0
+		}
+		n.count = int16(j)
+		return removed
+	}
+	children := n.children()
+	for i := 0; i < int(n.count); i++ {
+		if expired(n.exp(i), now) {
+			tr.cow(&children[i])
+			removed += tr.nodeSweep(children[i], now, onremove)
+			if children[i].count == 0 {
+				tr.free(children[i])
+				continue
+			}
+			n.rects[i] = children[i].rect()
+			n.exps[i] = children[i].minexp()
+		}
+		n.rects[j] = n.rects[i]
+		children[j] = children[i]
+		n.setexp(j, n.exp(i))
+		j++
+	}
+	for i := j; i < int(n.count); i++ {
+		children[i] = nil
+		if n.exps != nil {
+			n.exps[i] = 0
+		}
+	}
+	n.count = int16(j)
+	if orderBranches && !n.issorted() {
+		n.sort()
+	}
+	return removed
+}
+
+// SearchUnexpired searches for items in tree that intersect the provided
+// rectangle, skipping items that have expired at the provided time but have
+// not been removed by Sweep yet.
+func (tr *RTreeGN[N, T]) SearchUnexpired(min, max [2]N, now time.Time,
+	iter func(min, max [2]N, data T) bool,
+) {
+	target := rect[N]{min, max}
+	if tr.root == nil || !target.intersects(&tr.rect) {
+		return
+	}
+	tr.root.searchUnexpired(target, now.UnixNano(), iter)
+}
+
+func (n *node[N, T]) searchUnexpired(target rect[N], now int64,
+	iter func(min, max [2]N, data T) bool,
+) bool {
+	rects := n.rects[:n.count]
+	if n.leaf() {
+		items := n.items()
+		for i := 0; i < len(rects); i++ {
+			if rects[i].intersects(&target) && !expired(n.exp(i), now) {
+				if !iter(rects[i].min, rects[i].max, items[i]) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	children := n.children()
+	for i := 0; i < len(rects); i++ {
+		if target.intersects(&rects[i]) {
+			if !children[i].searchUnexpired(target, now, iter) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// InsertExpires inserts data into the tree that expires at the provided time.
+// Expired items are removed by Sweep. A zero time means that the item never
+// expires.
+func (tr *RTreeG[T]) InsertExpires(min, max [2]float64, data T,
+	expires time.Time,
+) {
+	tr.base.InsertExpires(min, max, data, expires)
+}
+
+// Sweep removes all items that have expired at the provided time, and
+// returns the number of items removed.
+func (tr *RTreeG[T]) Sweep(now time.Time) int {
+	return tr.base.Sweep(now)
+}
+
+// SearchUnexpired searches for items in tree that intersect the provided
+// rectangle, skipping items that have expired at the provided time but have
+// not been removed by Sweep yet.
+func (tr *RTreeG[T]) SearchUnexpired(min, max [2]float64, now time.Time,
+	iter func(min, max [2]float64, data T) bool,
+) {
+	tr.base.SearchUnexpired(min, max, now, iter)
+}
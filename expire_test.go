@@ -0,0 +1,92 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestExpires(t *testing.T) {
+	rand.Seed(seed)
+	N := 50_000
+	start := time.Unix(1000, 0)
+	exps := make(map[int]time.Time)
+	var tr RTreeG[int]
+	for i := 0; i < N; i++ {
+		r := randRect('m')
+		var exp time.Time
+		if i%3 != 0 {
+			exp = start.Add(time.Duration(rand.Intn(100)) * time.Second)
+		}
+		exps[i] = exp
+		tr.InsertExpires(r.min, r.max, i, exp)
+	}
+	// move some items, which should keep their expirations
+	var moved []int
+	var movedRects [][2][2]float64
+	tr.Scan(func(min, max [2]float64, i int) bool {
+		if i%10 == 0 {
+			moved = append(moved, i)
+			movedRects = append(movedRects, [2][2]float64{min, max})
+		}
+		return len(moved) < 100
+	})
+	for j, i := range moved {
+		min, max := movedRects[j][0], movedRects[j][1]
+		tr.Replace(min, max, i, min, min, i)
+	}
+	if err := rSane(&tr); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := tr.Copy()
+	var deleted []int
+	tr.Watch([2]float64{-180, -90}, [2]float64{180, 90},
+		func(ev WatchEvent[float64, int]) {
+			deleted = append(deleted, ev.OldData)
+		},
+	)
+	for s := 0; s <= 100; s += 10 {
+		now := start.Add(time.Duration(s) * time.Second)
+		var unexpired int
+		tr.SearchUnexpired([2]float64{-180, -90}, [2]float64{180, 90}, now,
+			func(min, max [2]float64, i int) bool {
+				unexpired++
+				return true
+			},
+		)
+		tr.Sweep(now)
+		if err := rSane(&tr); err != nil {
+			t.Fatal(err)
+		}
+		if tr.Len() != unexpired {
+			t.Fatalf("expected %d, got %d", unexpired, tr.Len())
+		}
+		var count int
+		tr.Scan(func(min, max [2]float64, i int) bool {
+			if exp := exps[i]; !exp.IsZero() && !exp.After(now) {
+				t.Fatalf("item %d not swept", i)
+			}
+			count++
+			return true
+		})
+		if count != tr.Len() {
+			t.Fatalf("expected %d, got %d", tr.Len(), count)
+		}
+	}
+	if tr.Len() != N/3+1 {
+		t.Fatalf("expected %d, got %d", N/3+1, tr.Len())
+	}
+	if len(deleted) != N-tr.Len() {
+		t.Fatalf("expected %d, got %d", N-tr.Len(), len(deleted))
+	}
+	if snapshot.Len() != N {
+		t.Fatalf("expected %d, got %d", N, snapshot.Len())
+	}
+	if err := rSane(snapshot); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import "math"
+
+// earthRadius is the mean radius of the Earth in meters.
+const earthRadius = 6371008.8
+
+const rad = math.Pi / 180
+
+// GeoBoxDist is like BoxDist but for trees where the rects are longitude and
+// latitude degrees, in that order, and the distance is the great-circle
+// distance in meters from the point at lat, lon. For branches, it's the
+// distance to the closest point of the box, which is a lower bound of the
+// distance to anything inside. Items use the same distance unless itemDist
+// is provided. Distances are measured across the antimeridian, and items that
+// were inserted with InsertGeo are supported.
+//
+//	tr.Nearby(
+//		rtree.GeoBoxDist[int](59.33, 18.07, nil),
+//		func(min, max [2]float64, data int, meters float64) bool {
+//			return true
+//		},
+//	)
+func GeoBoxDist[T any](lat, lon float64,
+	itemDist func(min, max [2]float64, data T) float64,
+) (dist func(min, max [2]float64, data T, item bool) float64) {
+	cosLat := math.Cos(lat * rad)
+	return func(min, max [2]float64, data T, item bool) float64 {
+		if item && itemDist != nil {
+			return itemDist(min, max, data)
+		}
+		h := geoBoxHaverSin(lon, lat, cosLat, min, max)
+		return 2 * earthRadius * math.Asin(math.Sqrt(h))
+	}
+}
+
+// geoBoxHaverSin returns the haversine of the angle from the point to the
+// closest point of the box.
+func geoBoxHaverSin(lon, lat, cosLat float64, min, max [2]float64) float64 {
+	if (lon >= min[0] && lon <= max[0]) ||
+		(lon+360 >= min[0] && lon+360 <= max[0]) {
+		// the point is between the box longitudes
+		if lat < min[1] {
+			return haverSin((lat - min[1]) * rad)
+		}
+		if lat > max[1] {
+			return haverSin((lat - max[1]) * rad)
+		}
+		return 0
+	}
+	// the point is west or east of the box, so the closest point is on the
+	// closest meridian, either at the vertex of the great circle or at one
+	// of the corners.
+	hsDLon := math.Min(haverSin((lon-min[0])*rad), haverSin((lon-max[0])*rad))
+	vlat := vertexLat(lat, hsDLon)
+	if vlat > min[1] && vlat < max[1] {
+		return haverSinDist(hsDLon, cosLat, lat, vlat)
+	}
+	return math.Min(
+		haverSinDist(hsDLon, cosLat, lat, min[1]),
+		haverSinDist(hsDLon, cosLat, lat, max[1]),
+	)
+}
+
+func haverSin(theta float64) float64 {
+	s := math.Sin(theta / 2)
+	return s * s
+}
+
+func haverSinDist(hsDLon, cosLat1, lat1, lat2 float64) float64 {
+	return cosLat1*math.Cos(lat2*rad)*hsDLon + haverSin((lat1-lat2)*rad)
+}
+
+// vertexLat returns the latitude where the great circle from the point at
+// lat, at a longitude difference of hsDLon, is closest to the meridian.
+func vertexLat(lat, hsDLon float64) float64 {
+	cosDLon := 1 - 2*hsDLon
+	if cosDLon <= 0 {
+		if lat > 0 {
+			return 90
+		}
+		return -90
+	}
+	return math.Atan(math.Tan(lat*rad)/cosDLon) / rad
+}
+
+// Geographic rects that cross the antimeridian have a min longitude that is
+// greater than the max longitude, such as 170 to -170. These are stored in
+// the tree with 360 added to the max longitude, 170 to 190, which keeps
+// min <= max for all nodes. SearchGeo searches both the normal and the
+// shifted longitudes, and returns rects in their original form.
+
+// geoWrap returns the rect in the form that it's stored in the tree.
+func geoWrap(min, max [2]float64) ([2]float64, [2]float64) {
+	if min[0] > max[0] {
+		max[0] += 360
+	}
+	return min, max
+}
+
+// geoUnwrap returns the stored rect in its original form.
+func geoUnwrap(min, max [2]float64) ([2]float64, [2]float64) {
+	if max[0] > 180 {
+		max[0] -= 360
+	}
+	return min, max
+}
+
+// geoWindow returns the search window with longitudes from -180 to 180, where
+// a window that crosses the antimeridian has a min longitude that is greater
+// than the max longitude. Windows that extend past the antimeridian, such as
+// the stored form 170 to 190 or -190 to -170, become 170 to -170, and windows
+// that are 360 degrees or wider become -180 to 180.
+func geoWindow(min, max [2]float64) ([2]float64, [2]float64) {
+	if min[0] <= max[0] && max[0]-min[0] >= 360 {
+		min[0], max[0] = -180, 180
+		return min, max
+	}
+	if min[0] < -180 {
+		min[0] += 360
+	}
+	if max[0] > 180 {
+		max[0] -= 360
+	}
+	return min, max
+}
+
+// InsertGeo inserts an item where the rect is longitude and latitude degrees,
+// in that order. When the min longitude is greater than the max longitude,
+// the rect crosses the antimeridian. Use SearchGeo to find these items.
+//
+// Rects that cross the antimeridian are stored with 360 added to the max
+// longitude, and are returned that way by the other search functions.
+func (tr *RTreeG[T]) InsertGeo(min, max [2]float64, data T) {
+	min, max = geoWrap(min, max)
+	tr.base.Insert(min, max, data)
+}
+
+// DeleteGeo deletes an item that was inserted with InsertGeo.
+func (tr *RTreeG[T]) DeleteGeo(min, max [2]float64, data T) {
+	min, max = geoWrap(min, max)
+	tr.base.Delete(min, max, data)
+}
+
+// ReplaceGeo replaces an item that was inserted with InsertGeo.
+func (tr *RTreeG[T]) ReplaceGeo(
+	oldMin, oldMax [2]float64, oldData T,
+	newMin, newMax [2]float64, newData T,
+) {
+	oldMin, oldMax = geoWrap(oldMin, oldMax)
+	newMin, newMax = geoWrap(newMin, newMax)
+	tr.base.Replace(oldMin, oldMax, oldData, newMin, newMax, newData)
+}
+
+// SearchGeo searches for items that intersect the rect, where the rect is
+// longitude and latitude degrees, in that order. When the min longitude is
+// greater than the max longitude, the rect crosses the antimeridian, such as
+// a viewport from 170 to -170. A rect that extends past the antimeridian,
+// such as the Bounds of a tree with crossing items, also wraps. Items that
+// cross the antimeridian are found from either side, and each item is
+// returned once, with its rect in the form that it was inserted with
+// InsertGeo.
+func (tr *RTreeG[T]) SearchGeo(min, max [2]float64,
+	iter func(min, max [2]float64, data T) bool,
+) {
+	min, max = geoWindow(min, max)
+	// The window is split into parts that don't wrap, and each part is
+	// searched again shifted by 360 for the stored crossing items.
+	var parts [4]rect[float64]
+	nparts := 0
+	add := func(minx, maxx float64) {
+		parts[nparts] = rect[float64]{
+			[2]float64{minx, min[1]}, [2]float64{maxx, max[1]},
+		}
+		parts[nparts+1] = rect[float64]{
+			[2]float64{minx + 360, min[1]}, [2]float64{maxx + 360, max[1]},
+		}
+		nparts += 2
+	}
+	if min[0] > max[0] {
+		add(min[0], 180)
+		add(-180, max[0])
+	} else {
+		add(min[0], max[0])
+	}
+	for i := 0; i < nparts; i++ {
+		done := false
+		tr.base.Search(parts[i].min, parts[i].max,
+			func(min, max [2]float64, data T) bool {
+				// skip items that were returned by an earlier part
+				r := rect[float64]{min, max}
+				for j := 0; j < i; j++ {
+					if r.intersects(&parts[j]) {
+						return true
+					}
+				}
+				min, max = geoUnwrap(min, max)
+				if !iter(min, max, data) {
+					done = true
+					return false
+				}
+				return true
+			},
+		)
+		if done {
+			return
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// haversine returns the great-circle distance in meters.
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	dlat := (lat2 - lat1) * rad
+	dlon := (lon2 - lon1) * rad
+	a := math.Sin(dlat/2)*math.Sin(dlat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dlon/2)*math.Sin(dlon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
+
+func TestGeoBoxDist(t *testing.T) {
+	rand.Seed(seed)
+	// the box distance is a lower bound for all points in the box
+	for i := 0; i < 10_000; i++ {
+		lat, lon := rand.Float64()*180-90, rand.Float64()*360-180
+		dist := GeoBoxDist[int](lat, lon, nil)
+		a, b := randTestPoint(), randTestPoint()
+		min := [2]float64{math.Min(a.x, b.x), math.Min(a.y, b.y)}
+		max := [2]float64{math.Max(a.x, b.x), math.Max(a.y, b.y)}
+		d := dist(min, max, 0, false)
+		for j := 0; j < 10; j++ {
+			x := min[0] + rand.Float64()*(max[0]-min[0])
+			y := min[1] + rand.Float64()*(max[1]-min[1])
+			if pd := haversine(lat, lon, y, x); d > pd+1e-6 {
+				t.Fatalf("box distance %f is greater than %f", d, pd)
+			}
+		}
+		if pd := haversine(lat, lon, a.y, a.x); math.Abs(
+			dist(a.coord(), a.coord(), 0, true)-pd) > 1e-6 {
+			t.Fatalf("expected %f, got %f", pd,
+				dist(a.coord(), a.coord(), 0, true))
+		}
+	}
+
+	// kNN around Stockholm returns the points in great-circle order
+	var tr RTreeG[testPoint]
+	pts := make([]testPoint, 10_000)
+	for i := range pts {
+		pts[i] = randTestPoint()
+		tr.Insert(pts[i].coord(), pts[i].coord(), pts[i])
+	}
+	lat, lon := 59.33, 18.07
+	dists := make([]float64, len(pts))
+	for i, pt := range pts {
+		dists[i] = haversine(lat, lon, pt.y, pt.x)
+	}
+	sort.Float64s(dists)
+	var i int
+	tr.Nearby(GeoBoxDist[testPoint](lat, lon, nil),
+		func(_, _ [2]float64, pt testPoint, meters float64) bool {
+			if math.Abs(meters-dists[i]) > 1e-6 {
+				t.Fatalf("item %d: expected %f, got %f", i, dists[i], meters)
+			}
+			i++
+			return i < 100
+		},
+	)
+	if i != 100 {
+		t.Fatalf("expected 100 items, got %d", i)
+	}
+}
+
+func TestSearchGeo(t *testing.T) {
+	rand.Seed(seed)
+	// lonRanges splits a longitude range that may cross the antimeridian
+	lonRanges := func(min, max float64) [][2]float64 {
+		if min > max {
+			return [][2]float64{{min, 180}, {-180, max}}
+		}
+		return [][2]float64{{min, max}}
+	}
+	randRect := func(size float64) (min, max [2]float64) {
+		x, y := rand.Float64()*360-180, rand.Float64()*170-85
+		x2 := x + rand.Float64()*size
+		if x2 > 180 {
+			x2 -= 360
+		}
+		return [2]float64{x, y}, [2]float64{x2, y + rand.Float64()*5}
+	}
+	var tr RTreeG[int]
+	N := 10_000
+	rects := make([][2][2]float64, N)
+	for i := range rects {
+		rects[i][0], rects[i][1] = randRect(20)
+		tr.InsertGeo(rects[i][0], rects[i][1], i)
+	}
+	for k := 0; k < 500; k++ {
+		min, max := randRect(60)
+		if k%10 == 0 {
+			min[0], max[0] = 175, -175
+		}
+		expect := make(map[int]bool)
+		for i, r := range rects {
+			if r[0][1] > max[1] || r[1][1] < min[1] {
+				continue
+			}
+			for _, a := range lonRanges(r[0][0], r[1][0]) {
+				for _, b := range lonRanges(min[0], max[0]) {
+					if a[0] <= b[1] && b[0] <= a[1] {
+						expect[i] = true
+					}
+				}
+			}
+		}
+		seen := make(map[int]bool)
+		tr.SearchGeo(min, max, func(min, max [2]float64, i int) bool {
+			if !expect[i] || seen[i] {
+				t.Fatalf("unexpected item %d", i)
+			}
+			if min != rects[i][0] || max != rects[i][1] {
+				t.Fatalf("expected the original rect")
+			}
+			seen[i] = true
+			return true
+		})
+		if len(seen) != len(expect) {
+			t.Fatalf("expected %d items, got %d", len(expect), len(seen))
+		}
+	}
+	for i, r := range rects {
+		if i%2 == 0 {
+			tr.DeleteGeo(r[0], r[1], i)
+		} else {
+			tr.ReplaceGeo(r[0], r[1], i, r[0], r[1], -i)
+		}
+	}
+	if tr.Len() != N/2 {
+		t.Fatalf("expected %d items, got %d", N/2, tr.Len())
+	}
+
+	// points on either side of the antimeridian are close
+	var tr2 RTreeG[int]
+	tr2.Insert([2]float64{-179.9, 10}, [2]float64{-179.9, 10}, 1)
+	tr2.Insert([2]float64{170, 10}, [2]float64{170, 10}, 2)
+	tr2.InsertGeo([2]float64{175, 20}, [2]float64{-175, 30}, 3)
+	var order []int
+	tr2.Nearby(GeoBoxDist[int](25, -178, nil),
+		func(_, _ [2]float64, i int, _ float64) bool {
+			order = append(order, i)
+			return true
+		},
+	)
+	if len(order) != 3 || order[0] != 3 || order[1] != 1 || order[2] != 2 {
+		t.Fatalf("unexpected order %v", order)
+	}
+}
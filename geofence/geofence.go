@@ -0,0 +1,221 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Package geofence detects when moving objects enter, exit, or cross static
+// rectangular fences.
+package geofence
+
+import (
+	"math"
+
+	"github.com/tidwall/rtree"
+)
+
+// Detect is the kind of geofence event.
+type Detect int8
+
+const (
+	// Enter is when an object moves into a fence.
+	Enter Detect = iota
+	// Exit is when an object moves out of a fence.
+	Exit
+	// Inside is when an object moves while staying inside a fence.
+	Inside
+	// Cross is when an object passes through a fence, starting and ending
+	// outside of it.
+	Cross
+)
+
+func (d Detect) String() string {
+	switch d {
+	case Enter:
+		return "enter"
+	case Exit:
+		return "exit"
+	case Inside:
+		return "inside"
+	case Cross:
+		return "cross"
+	}
+	return "unknown"
+}
+
+// Event is emitted for each fence that an object write affects.
+type Event[F, O any] struct {
+	Detect   Detect
+	Fence    F
+	FenceMin [2]float64
+	FenceMax [2]float64
+	Object   O
+	Min      [2]float64
+	Max      [2]float64
+}
+
+// Geofence tracks objects against fences.
+// Fences and objects are stored in two separate R-trees.
+type Geofence[F, O any] struct {
+	fences  rtree.RTreeG[F]
+	objects rtree.RTreeG[O]
+	fn      func(ev Event[F, O])
+	// the rect of the last object that was deleted from the objects
+	deleted        bool
+	delMin, delMax [2]float64
+}
+
+// New returns a new Geofence that calls fn for each event.
+func New[F, O any](fn func(ev Event[F, O])) *Geofence[F, O] {
+	g := &Geofence[F, O]{fn: fn}
+	// A delete removes any object that is inside of the provided rect, so
+	// the events use the rect of the object that was actually removed.
+	inf := math.Inf(1)
+	g.objects.Watch([2]float64{-inf, -inf}, [2]float64{inf, inf},
+		func(ev rtree.WatchEvent[float64, O]) {
+			if ev.Op == rtree.WatchDelete {
+				g.deleted = true
+				g.delMin, g.delMax = ev.OldMin, ev.OldMax
+			}
+		},
+	)
+	return g
+}
+
+// delete removes an object and returns the rect that it had.
+func (g *Geofence[F, O]) delete(min, max [2]float64, obj O,
+) (dmin, dmax [2]float64, ok bool) {
+	g.deleted = false
+	g.objects.Delete(min, max, obj)
+	return g.delMin, g.delMax, g.deleted
+}
+
+// InsertFence adds a fence.
+func (g *Geofence[F, O]) InsertFence(min, max [2]float64, fence F) {
+	g.fences.Insert(min, max, fence)
+}
+
+// DeleteFence removes a fence.
+func (g *Geofence[F, O]) DeleteFence(min, max [2]float64, fence F) {
+	g.fences.Delete(min, max, fence)
+}
+
+// Insert adds an object. An Enter event is emitted for each fence that the
+// object intersects.
+func (g *Geofence[F, O]) Insert(min, max [2]float64, obj O) {
+	g.objects.Insert(min, max, obj)
+	g.fences.Search(min, max, func(fmin, fmax [2]float64, fence F) bool {
+		g.fn(Event[F, O]{Enter, fence, fmin, fmax, obj, min, max})
+		return true
+	})
+}
+
+// Delete removes an object. An Exit event is emitted for each fence that the
+// object intersected.
+func (g *Geofence[F, O]) Delete(min, max [2]float64, obj O) {
+	min, max, ok := g.delete(min, max, obj)
+	if !ok {
+		return
+	}
+	g.fences.Search(min, max, func(fmin, fmax [2]float64, fence F) bool {
+		g.fn(Event[F, O]{Exit, fence, fmin, fmax, obj, min, max})
+		return true
+	})
+}
+
+// Replace moves an object from its old rect to a new rect.
+// If the old object does not exist then nothing happens.
+//
+// Each fence that intersects the old rect, the new rect, or the straight path
+// between the centers of the two rects will emit one event: Enter when only
+// the new rect intersects, Exit when only the old rect intersects, Inside when
+// both intersect, and Cross when only the path intersects.
+func (g *Geofence[F, O]) Replace(
+	oldMin, oldMax [2]float64, oldObj O,
+	newMin, newMax [2]float64, newObj O,
+) {
+	oldMin, oldMax, ok := g.delete(oldMin, oldMax, oldObj)
+	if !ok {
+		return
+	}
+	g.objects.Insert(newMin, newMax, newObj)
+
+	// search the area that covers both rects
+	smin, smax := oldMin, oldMax
+	for i := 0; i < 2; i++ {
+		if newMin[i] < smin[i] {
+			smin[i] = newMin[i]
+		}
+		if newMax[i] > smax[i] {
+			smax[i] = newMax[i]
+		}
+	}
+	a := [2]float64{(oldMin[0] + oldMax[0]) / 2, (oldMin[1] + oldMax[1]) / 2}
+	b := [2]float64{(newMin[0] + newMax[0]) / 2, (newMin[1] + newMax[1]) / 2}
+	g.fences.Search(smin, smax, func(fmin, fmax [2]float64, fence F) bool {
+		inOld := intersects(fmin, fmax, oldMin, oldMax)
+		inNew := intersects(fmin, fmax, newMin, newMax)
+		var detect Detect
+		switch {
+		case inOld && inNew:
+			detect = Inside
+		case inNew:
+			detect = Enter
+		case inOld:
+			detect = Exit
+		case segmentIntersects(a, b, fmin, fmax):
+			detect = Cross
+		default:
+			return true
+		}
+		g.fn(Event[F, O]{detect, fence, fmin, fmax, newObj, newMin, newMax})
+		return true
+	})
+}
+
+// SearchFences searches for fences that intersect the provided rectangle.
+func (g *Geofence[F, O]) SearchFences(min, max [2]float64,
+	iter func(min, max [2]float64, fence F) bool,
+) {
+	g.fences.Search(min, max, iter)
+}
+
+// SearchObjects searches for objects that intersect the provided rectangle.
+func (g *Geofence[F, O]) SearchObjects(min, max [2]float64,
+	iter func(min, max [2]float64, obj O) bool,
+) {
+	g.objects.Search(min, max, iter)
+}
+
+func intersects(amin, amax, bmin, bmax [2]float64) bool {
+	return !(bmin[0] > amax[0] || bmax[0] < amin[0] ||
+		bmin[1] > amax[1] || bmax[1] < amin[1])
+}
+
+// segmentIntersects returns true if the segment a->b intersects the rect,
+// using the slab method.
+func segmentIntersects(a, b, min, max [2]float64) bool {
+	t0, t1 := 0.0, 1.0
+	for i := 0; i < 2; i++ {
+		d := b[i] - a[i]
+		if d == 0 {
+			if a[i] < min[i] || a[i] > max[i] {
+				return false
+			}
+			continue
+		}
+		tn := (min[i] - a[i]) / d
+		tf := (max[i] - a[i]) / d
+		if tn > tf {
+			tn, tf = tf, tn
+		}
+		if tn > t0 {
+			t0 = tn
+		}
+		if tf < t1 {
+			t1 = tf
+		}
+		if t0 > t1 {
+			return false
+		}
+	}
+	return true
+}
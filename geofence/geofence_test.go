@@ -0,0 +1,56 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geofence
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGeofence(t *testing.T) {
+	var events []string
+	g := New(func(ev Event[string, int]) {
+		events = append(events, fmt.Sprintf("%s:%s:%d", ev.Fence, ev.Detect,
+			ev.Object))
+	})
+	g.InsertFence([2]float64{0, 0}, [2]float64{10, 10}, "a")
+	g.InsertFence([2]float64{20, 0}, [2]float64{30, 10}, "b")
+	g.InsertFence([2]float64{40, 0}, [2]float64{50, 10}, "c")
+	g.InsertFence([2]float64{20, 40}, [2]float64{30, 50}, "d")
+
+	pt := func(x, y float64) [2]float64 { return [2]float64{x, y} }
+	g.Insert(pt(5, 5), pt(5, 5), 1)
+	g.Replace(pt(5, 5), pt(5, 5), 1, pt(6, 6), pt(6, 6), 1)
+	g.Replace(pt(6, 6), pt(6, 6), 1, pt(45, 5), pt(45, 5), 1)
+	g.Replace(pt(9, 9), pt(9, 9), 1, pt(9, 8), pt(9, 8), 1) // missing
+	g.Delete(pt(45, 5), pt(45, 5), 1)
+	exp := "[a:enter:1 a:inside:1 a:exit:1 b:cross:1 c:enter:1 c:exit:1]"
+	if fmt.Sprint(events) != exp {
+		t.Fatalf("expected %s, got %s", exp, events)
+	}
+}
+
+func TestGeofenceLooseDelete(t *testing.T) {
+	var events []string
+	g := New(func(ev Event[string, int]) {
+		events = append(events, fmt.Sprintf("%s:%s:%d:%v", ev.Fence,
+			ev.Detect, ev.Object, ev.Min))
+	})
+	g.InsertFence([2]float64{0, 0}, [2]float64{10, 10}, "near")
+	g.InsertFence([2]float64{50, 50}, [2]float64{60, 60}, "far")
+	pt := func(x, y float64) [2]float64 { return [2]float64{x, y} }
+	g.Insert(pt(5, 5), pt(5, 5), 1)
+	g.Insert(pt(90, 90), pt(90, 90), 2)
+	g.Insert(pt(6, 6), pt(6, 6), 3)
+	events = nil
+	// the rects only need to contain the objects, and the events are for the
+	// rects of the objects
+	g.Delete(pt(5, 5), pt(80, 80), 1)
+	g.Replace(pt(6, 6), pt(80, 80), 3, pt(55, 55), pt(55, 55), 3)
+	exp := "[near:exit:1:[5 5] near:exit:3:[55 55] far:enter:3:[55 55]]"
+	if fmt.Sprint(events) != exp {
+		t.Fatalf("expected %s, got %s", exp, events)
+	}
+}
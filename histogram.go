@@ -0,0 +1,116 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import "math"
+
+// Histogram returns the number of items in each cell of a grid of cols by
+// rows over the rectangle. The grid is indexed by [row][col], where row zero
+// is at min[1] and col zero is at min[0].
+//
+// When overlap is false, each item is counted once, in the cell that contains
+// the center of the item, and items with a center outside of the rectangle
+// are not counted. When overlap is true, each item is counted in every cell
+// that it intersects.
+//
+// Nodes that are fully inside of one cell add their count without visiting
+// their items, so large grids over dense data are much faster than a Search.
+func (tr *RTreeGN[N, T]) Histogram(min, max [2]N, cols, rows int,
+	overlap bool,
+) [][]int {
+	if cols < 1 || rows < 1 {
+		return nil
+	}
+	h := histogram[N, T]{
+		target:  rect[N]{min, max},
+		min:     [2]float64{float64(min[0]), float64(min[1])},
+		overlap: overlap,
+		n:       [2]int{cols, rows},
+		grid:    make([][]int, rows),
+	}
+	h.size[0] = (float64(max[0]) - h.min[0]) / float64(cols)
+	h.size[1] = (float64(max[1]) - h.min[1]) / float64(rows)
+	cells := make([]int, cols*rows)
+	for i := range h.grid {
+		h.grid[i] = cells[i*cols : (i+1)*cols]
+	}
+	if tr.root != nil && h.target.intersects(&tr.rect) {
+		h.node(tr.root)
+	}
+	return h.grid
+}
+
+type histogram[N numeric, T any] struct {
+	target  rect[N]
+	min     [2]float64 // grid origin
+	size    [2]float64 // cell size
+	n       [2]int     // cols and rows
+	overlap bool
+	grid    [][]int
+}
+
+// cell returns the grid index of the value on an axis, clamped to the grid.
+func (h *histogram[N, T]) cell(v N, axis int) int {
+	if h.size[axis] == 0 {
+		return 0
+	}
+	i := int(math.Floor((float64(v) - h.min[axis]) / h.size[axis]))
+	if i < 0 {
+		return 0
+	}
+	if i >= h.n[axis] {
+		return h.n[axis] - 1
+	}
+	return i
+}
+
+func (h *histogram[N, T]) node(n *node[N, T]) {
+	rects := n.rects[:n.count]
+	for i := range rects {
+		r := &rects[i]
+		if !h.target.intersects(r) {
+			continue
+		}
+		if n.leaf() {
+			h.item(r)
+			continue
+		}
+		if h.target.contains(r) {
+			col, row := h.cell(r.min[0], 0), h.cell(r.min[1], 1)
+			if col == h.cell(r.max[0], 0) && row == h.cell(r.max[1], 1) {
+				// the whole node is in one cell
+				h.grid[row][col] += n.children()[i].deepCount()
+				continue
+			}
+		}
+		h.node(n.children()[i])
+	}
+}
+
+func (h *histogram[N, T]) item(r *rect[N]) {
+	if !h.overlap {
+		c := r.center()
+		if h.target.contains(&rect[N]{c, c}) {
+			h.grid[h.cell(c[1], 1)][h.cell(c[0], 0)]++
+		}
+		return
+	}
+	col0, col1 := h.cell(r.min[0], 0), h.cell(r.max[0], 0)
+	row0, row1 := h.cell(r.min[1], 1), h.cell(r.max[1], 1)
+	for row := row0; row <= row1; row++ {
+		for col := col0; col <= col1; col++ {
+			h.grid[row][col]++
+		}
+	}
+}
+
+// Histogram returns the number of items in each cell of a grid of cols by
+// rows over the rectangle, indexed by [row][col]. Items are counted by their
+// center, or in every cell that they intersect when overlap is true.
+func (tr *RTreeG[T]) Histogram(min, max [2]float64, cols, rows int,
+	overlap bool,
+) [][]int {
+	return tr.base.Histogram(min, max, cols, rows, overlap)
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestHistogram(t *testing.T) {
+	rand.Seed(seed)
+	N := 50_000
+	rects := make([]rect[float64], N)
+	var tr RTreeG[int]
+	for i := range rects {
+		x, y := rand.Float64()*1000, rand.Float64()*1000
+		rects[i] = rect[float64]{
+			[2]float64{x, y},
+			[2]float64{x + rand.Float64()*5, y + rand.Float64()*5},
+		}
+		tr.Insert(rects[i].min, rects[i].max, i)
+	}
+	if tr.Histogram([2]float64{0, 0}, [2]float64{1, 1}, 0, 1, false) != nil {
+		t.Fatalf("expected nil")
+	}
+	for k := 0; k < 20; k++ {
+		x, y := rand.Float64()*800, rand.Float64()*800
+		min := [2]float64{x, y}
+		max := [2]float64{x + 10 + rand.Float64()*500, y + 10 + rand.Float64()*500}
+		if k == 0 {
+			min, max = [2]float64{0, 0}, [2]float64{1010, 1010}
+		}
+		cols, rows := 1+rand.Intn(50), 1+rand.Intn(50)
+		overlap := k%2 == 1
+		cw, ch := (max[0]-min[0])/float64(cols), (max[1]-min[1])/float64(rows)
+		cell := func(v, min, size float64, n int) int {
+			return int(math.Max(0, math.Min(float64(n-1),
+				math.Floor((v-min)/size))))
+		}
+		expect := make([][]int, rows)
+		for i := range expect {
+			expect[i] = make([]int, cols)
+		}
+		target := rect[float64]{min, max}
+		var total int
+		for _, r := range rects {
+			if !overlap {
+				c := r.center()
+				if target.contains(&rect[float64]{c, c}) {
+					expect[cell(c[1], min[1], ch, rows)][cell(c[0], min[0], cw,
+						cols)]++
+					total++
+				}
+				continue
+			}
+			if !target.intersects(&r) {
+				continue
+			}
+			for row := 0; row < rows; row++ {
+				for col := 0; col < cols; col++ {
+					c := rect[float64]{
+						[2]float64{min[0] + float64(col)*cw,
+							min[1] + float64(row)*ch},
+						[2]float64{min[0] + float64(col+1)*cw,
+							min[1] + float64(row+1)*ch},
+					}
+					if r.intersects(&c) {
+						expect[row][col]++
+					}
+				}
+			}
+		}
+		grid := tr.Histogram(min, max, cols, rows, overlap)
+		if len(grid) != rows {
+			t.Fatalf("expected %d rows, got %d", rows, len(grid))
+		}
+		for row := range grid {
+			for col := range grid[row] {
+				if grid[row][col] != expect[row][col] {
+					t.Fatalf("%d/%d: expected %d, got %d", row, col,
+						expect[row][col], grid[row][col])
+				}
+			}
+		}
+		if k == 0 && total != N {
+			t.Fatalf("expected %d items, got %d", N, total)
+		}
+	}
+}
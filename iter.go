@@ -0,0 +1,111 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import "sync"
+
+// Iterator is a pull-based search over the items that intersect a rectangle.
+// It returns the same items, in the same order, as Search.
+//
+// The iterator holds on to the nodes of the tree, so the tree must not be
+// modified while iterating. To iterate while writing, iterate over a Copy of
+// the tree. The copy is a snapshot that is not affected by writes to the
+// original.
+type Iterator[N numeric, T any] struct {
+	pool   *sync.Pool
+	root   *node[N, T]
+	bounds rect[N]
+	target rect[N]
+	stack  []iterFrame[N, T]
+	cur    rect[N]
+	item   T
+}
+
+type iterFrame[N numeric, T any] struct {
+	n *node[N, T]
+	i int
+}
+
+// Iter returns an iterator over all items that intersect the provided
+// rectangle. Call Release when done with the iterator.
+func (tr *RTreeGN[N, T]) Iter(min, max [2]N) *Iterator[N, T] {
+	var it *Iterator[N, T]
+	if tr.ipool != nil {
+		it = tr.ipool.Get().(*Iterator[N, T])
+		it.pool = tr.ipool
+	} else {
+		it = new(Iterator[N, T])
+	}
+	it.root = tr.root
+	it.bounds = tr.rect
+	it.Seek(min, max)
+	return it
+}
+
+// Seek restarts the iterator with a new rectangle.
+func (it *Iterator[N, T]) Seek(min, max [2]N) {
+	it.target = rect[N]{min, max}
+	it.stack = it.stack[:0]
+	if it.root != nil && it.target.intersects(&it.bounds) {
+		it.stack = append(it.stack, iterFrame[N, T]{n: it.root})
+	}
+}
+
+// Next moves to the next item, and returns false when there are no more
+// items.
+func (it *Iterator[N, T]) Next() bool {
+	for len(it.stack) > 0 {
+		f := &it.stack[len(it.stack)-1]
+		if f.i == int(f.n.count) {
+			it.stack = it.stack[:len(it.stack)-1]
+			continue
+		}
+		n, i := f.n, f.i
+		f.i++
+		if !n.rects[i].intersects(&it.target) {
+			continue
+		}
+		if n.leaf() {
+			it.cur = n.rects[i]
+			it.item = n.items()[i]
+			return true
+		}
+		it.stack = append(it.stack, iterFrame[N, T]{n: n.children()[i]})
+	}
+	return false
+}
+
+// Rect returns the rectangle of the current item.
+func (it *Iterator[N, T]) Rect() (min, max [2]N) {
+	return it.cur.min, it.cur.max
+}
+
+// Item returns the data of the current item.
+func (it *Iterator[N, T]) Item() T {
+	return it.item
+}
+
+// Release returns the iterator to a pool. The iterator must not be used
+// after it's released.
+func (it *Iterator[N, T]) Release() {
+	var empty T
+	for i := range it.stack {
+		it.stack[i] = iterFrame[N, T]{}
+	}
+	it.stack = it.stack[:0]
+	it.root = nil
+	it.item = empty
+	if it.pool != nil {
+		pool := it.pool
+		it.pool = nil
+		pool.Put(it)
+	}
+}
+
+// Iter returns an iterator over all items that intersect the provided
+// rectangle. Call Release when done with the iterator.
+func (tr *RTreeG[T]) Iter(min, max [2]float64) *Iterator[float64, T] {
+	return tr.base.Iter(min, max)
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestIter(t *testing.T) {
+	rand.Seed(seed)
+	var tr RTreeG[testPoint]
+	it := tr.Iter([2]float64{-180, -90}, [2]float64{180, 90})
+	if it.Next() {
+		t.Fatalf("expected no items")
+	}
+	it.Release()
+	pts := make([]testPoint, 20_000)
+	for i := range pts {
+		pts[i] = randTestPoint()
+		tr.Insert(pts[i].coord(), pts[i].coord(), pts[i])
+	}
+	search := func(tr *RTreeG[testPoint], min, max [2]float64) []testPoint {
+		var res []testPoint
+		tr.Search(min, max, func(_, _ [2]float64, pt testPoint) bool {
+			res = append(res, pt)
+			return true
+		})
+		return res
+	}
+	check := func(it *Iterator[float64, testPoint], expect []testPoint) {
+		t.Helper()
+		var i int
+		for ; it.Next(); i++ {
+			min, max := it.Rect()
+			if i >= len(expect) || it.Item() != expect[i] ||
+				min != expect[i].coord() || max != expect[i].coord() {
+				t.Fatalf("item %d does not match", i)
+			}
+		}
+		if i != len(expect) {
+			t.Fatalf("expected %d items, got %d", len(expect), i)
+		}
+	}
+	min, max := [2]float64{-50, -20}, [2]float64{30, 40}
+	it = tr.Iter(min, max)
+	check(it, search(&tr, min, max))
+	it.Seek([2]float64{-180, -90}, [2]float64{180, 90})
+	check(it, search(&tr, [2]float64{-180, -90}, [2]float64{180, 90}))
+	it.Release()
+
+	// iterate a snapshot while the original changes
+	snap := tr.Copy()
+	expect := search(snap, min, max)
+	it = snap.Iter(min, max)
+	for i := 0; i < len(expect)/2; i++ {
+		it.Next()
+	}
+	for i, pt := range pts {
+		if i%2 == 0 {
+			tr.Delete(pt.coord(), pt.coord(), pt)
+		} else {
+			pt2 := randTestPoint()
+			tr.Replace(pt.coord(), pt.coord(), pt, pt2.coord(), pt2.coord(),
+				pt2)
+		}
+	}
+	check(it, expect[len(expect)/2:])
+	it.Release()
+}
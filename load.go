@@ -0,0 +1,297 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import "sync"
+
+// Load inserts many items at once.
+// The mins, maxs, and items slices must have the same lengths, and each
+// element from all slices must be associated.
+//
+// When the tree is empty, the tree is bulk loaded using the Sort-Tile-Recursive
+// algorithm, which is much faster than inserting the items one at a time and
+// produces a tree with full nodes and little overlap. Otherwise the items are
+// inserted one at a time.
+func (tr *RTreeGN[N, T]) Load(mins, maxs [][2]N, items []T) {
+	tr.load(mins, maxs, items, 1)
+}
+
+// ParallelLoad is like Load but sorts and packs the items using multiple
+// goroutines. The resulting tree is exactly the same as the one produced by
+// Load. A workers value of zero or less uses GOMAXPROCS.
+func (tr *RTreeGN[N, T]) ParallelLoad(mins, maxs [][2]N, items []T,
+	workers int,
+) {
+	tr.load(mins, maxs, items, parallelWorkers(workers))
+}
+
+func (tr *RTreeGN[N, T]) load(mins, maxs [][2]N, items []T, workers int) {
+	if len(items) == 0 {
+		return
+	}
+	if tr.root != nil {
+		for i := range items {
+			tr.Insert(mins[i], maxs[i], items[i])
+		}
+		return
+	}
+	rects := make([]rect[N], len(items))
+	for i := range rects {
+		rects[i] = rect[N]{mins[i], maxs[i]}
+	}
+	tr.init()
+	tr.mods++
+	tr.root, tr.rect = tr.pack(rects, items, nil, workers)
+	tr.count = len(items)
+	if tr.watch != nil {
+		for i := range items {
+			tr.watch.notify(WatchEvent[N, T]{
+				Op: WatchInsert, NewMin: mins[i], NewMax: maxs[i],
+				NewData: items[i],
+			})
+		}
+	}
+}
+
+// pack builds a tree from the bottom up, one level at a time, and returns the
+// root node and its rect. The exps are the item expirations, and may be nil.
+func (tr *RTreeGN[N, T]) pack(rects []rect[N], items []T, exps []int64,
+	workers int,
+) (*node[N, T], rect[N]) {
+	var children []*node[N, T]
+	for {
+		rects, children = tr.packLevel(rects, items, exps, children, workers)
+		items, exps = nil, nil
+		if len(children) == 1 {
+			return children[0], rects[0]
+		}
+	}
+}
+
+// packLevel groups the entries of one level into nodes using the
+// Sort-Tile-Recursive algorithm. The entries are either items or child nodes.
+// The entries are first sorted by their centers on the x-axis and cut into
+// vertical slices, then each slice is sorted by the y-axis and cut into
+// nodes. Ties are broken by the entry index, which makes the result the same
+// for any number of workers.
+func (tr *RTreeGN[N, T]) packLevel(rects []rect[N], items []T, exps []int64,
+	children []*node[N, T], workers int,
+) ([]rect[N], []*node[N, T]) {
+	isleaf := items != nil
+	keys := make([]lkey[N], len(rects))
+	for i := range keys {
+		keys[i] = lkey[N]{
+			c: [2]N{
+				rects[i].min[0] + rects[i].max[0],
+				rects[i].min[1] + rects[i].max[1],
+			},
+			idx: i,
+		}
+	}
+	psort(keys, 0, workers)
+
+	nnodes := (len(keys) + maxEntries - 1) / maxEntries
+	nslices := 1
+	for nslices*nslices < nnodes {
+		nslices++
+	}
+	sliceSize := nslices * maxEntries
+	nrects := make([]rect[N], nnodes)
+	nchildren := make([]*node[N, T], nnodes)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for s := 0; s*sliceSize < len(keys); s++ {
+		slice := keys[s*sliceSize:]
+		if len(slice) > sliceSize {
+			slice = slice[:sliceSize]
+		}
+		first := s * nslices // the first node index for this slice
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			ksort(slice, 1)
+			for i := 0; i < len(slice); i += maxEntries {
+				group := slice[i:]
+				if len(group) > maxEntries {
+					group = group[:maxEntries]
+				}
+				n := tr.newNode(isleaf)
+				for j, key := range group {
+					n.rects[j] = rects[key.idx]
+					if isleaf {
+						n.items()[j] = items[key.idx]
+						if exps != nil {
+							n.setexp(j, exps[key.idx])
+						}
+					} else {
+						n.children()[j] = children[key.idx]
+						n.setexp(j, children[key.idx].minexp())
+					}
+				}
+				n.count = int16(len(group))
+				if (orderBranches && !isleaf) || (orderLeaves && isleaf) {
+					n.sort()
+				}
+				nrects[first+i/maxEntries] = n.rect()
+				nchildren[first+i/maxEntries] = n
+			}
+		}()
+	}
+	wg.Wait()
+	return nrects, nchildren
+}
+
+// lkey is the sort key for an entry while loading.
+type lkey[N numeric] struct {
+	c   [2]N // center, times two
+	idx int  // entry index
+}
+
+func (a *lkey[N]) less(b *lkey[N], axis int) bool {
+	if a.c[axis] < b.c[axis] {
+		return true
+	}
+	if b.c[axis] < a.c[axis] {
+		return false
+	}
+	return a.idx < b.idx
+}
+
+// ksort sorts the keys on an axis.
+func ksort[N numeric](keys []lkey[N], axis int) {
+	for len(keys) > 12 {
+		// median of three pivot
+		m := len(keys) / 2
+		if keys[m].less(&keys[0], axis) {
+			keys[m], keys[0] = keys[0], keys[m]
+		}
+		if keys[len(keys)-1].less(&keys[m], axis) {
+			keys[len(keys)-1], keys[m] = keys[m], keys[len(keys)-1]
+			if keys[m].less(&keys[0], axis) {
+				keys[m], keys[0] = keys[0], keys[m]
+			}
+		}
+		pivot := keys[m]
+		i, j := 0, len(keys)-1
+		for i <= j {
+			for keys[i].less(&pivot, axis) {
+				i++
+			}
+			for pivot.less(&keys[j], axis) {
+				j--
+			}
+			if i <= j {
+				keys[i], keys[j] = keys[j], keys[i]
+				i++
+				j--
+			}
+		}
+		// recurse into the smaller side
+		if j+1 < len(keys)-i {
+			ksort(keys[:j+1], axis)
+			keys = keys[i:]
+		} else {
+			ksort(keys[i:], axis)
+			keys = keys[:j+1]
+		}
+	}
+	for i := 1; i < len(keys); i++ {
+		for j := i; j > 0 && keys[j].less(&keys[j-1], axis); j-- {
+			keys[j], keys[j-1] = keys[j-1], keys[j]
+		}
+	}
+}
+
+// psort sorts the keys on an axis using multiple goroutines. Keys are a
+// strict total order, which makes the result the same as ksort.
+func psort[N numeric](keys []lkey[N], axis int, workers int) {
+	const minChunk = 4096
+	nchunks := workers
+	if len(keys)/nchunks < minChunk {
+		nchunks = len(keys) / minChunk
+	}
+	if nchunks < 2 {
+		ksort(keys, axis)
+		return
+	}
+	bounds := make([]int, nchunks+1)
+	for i := range bounds {
+		bounds[i] = len(keys) * i / nchunks
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < nchunks; i++ {
+		wg.Add(1)
+		go func(chunk []lkey[N]) {
+			defer wg.Done()
+			ksort(chunk, axis)
+		}(keys[bounds[i]:bounds[i+1]])
+	}
+	wg.Wait()
+
+	// merge pairs of sorted chunks until there's only one
+	src, dst := keys, make([]lkey[N], len(keys))
+	for len(bounds) > 2 {
+		var next []int
+		for i := 0; i < len(bounds)-1; i += 2 {
+			next = append(next, bounds[i])
+			if i+2 == len(bounds) {
+				// odd one out
+				copy(dst[bounds[i]:bounds[i+1]], src[bounds[i]:bounds[i+1]])
+				continue
+			}
+			wg.Add(1)
+			go func(s, m, e int) {
+				defer wg.Done()
+				merge(dst[s:e], src[s:m], src[m:e], axis)
+			}(bounds[i], bounds[i+1], bounds[i+2])
+		}
+		next = append(next, len(keys))
+		wg.Wait()
+		bounds = next
+		src, dst = dst, src
+	}
+	if &src[0] != &keys[0] {
+		copy(keys, src)
+	}
+}
+
+func merge[N numeric](dst, a, b []lkey[N], axis int) {
+	var i, j, k int
+	for i < len(a) && j < len(b) {
+		if b[j].less(&a[i], axis) {
+			dst[k] = b[j]
+			j++
+		} else {
+			dst[k] = a[i]
+			i++
+		}
+		k++
+	}
+	k += copy(dst[k:], a[i:])
+	copy(dst[k:], b[j:])
+}
+
+// Load inserts many items at once.
+// The mins, maxs, and items slices must have the same lengths, and each
+// element from all slices must be associated.
+// When the tree is empty, the tree is bulk loaded. Otherwise the items are
+// inserted one at a time.
+func (tr *RTreeG[T]) Load(mins, maxs [][2]float64, items []T) {
+	tr.base.Load(mins, maxs, items)
+}
+
+// ParallelLoad is like Load but sorts and packs the items using multiple
+// goroutines. The resulting tree is exactly the same as the one produced by
+// Load.
+func (tr *RTreeG[T]) ParallelLoad(mins, maxs [][2]float64, items []T,
+	workers int,
+) {
+	tr.base.ParallelLoad(mins, maxs, items, workers)
+}
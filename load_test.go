@@ -0,0 +1,86 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func nodesEqual[T comparable](a, b *node[float64, T]) error {
+	if a.kind != b.kind || a.count != b.count {
+		return errors.New("node mismatch")
+	}
+	for i := 0; i < int(a.count); i++ {
+		if a.rects[i] != b.rects[i] {
+			return errors.New("rect mismatch")
+		}
+		if a.leaf() {
+			if a.items()[i] != b.items()[i] {
+				return errors.New("item mismatch")
+			}
+		} else if err := nodesEqual(a.children()[i], b.children()[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestLoad(t *testing.T) {
+	rand.Seed(seed)
+	for _, N := range []int{1, 64, 65, 5_000, 200_000} {
+		mins := make([][2]float64, N)
+		maxs := make([][2]float64, N)
+		items := make([]int, N)
+		for i := 0; i < N; i++ {
+			r := randRect('m')
+			mins[i], maxs[i], items[i] = r.min, r.max, i
+		}
+		var tr1 RTreeG[int]
+		tr1.Load(mins, maxs, items)
+		if tr1.Len() != N {
+			t.Fatalf("expected %d, got %d", N, tr1.Len())
+		}
+		if err := rSane(&tr1); err != nil {
+			t.Fatal(err)
+		}
+		for _, workers := range []int{0, 2, 7} {
+			var tr2 RTreeG[int]
+			tr2.ParallelLoad(mins, maxs, items, workers)
+			if err := nodesEqual(tr1.base.root, tr2.base.root); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var count int
+		tr1.Search([2]float64{-90, -45}, [2]float64{90, 45},
+			func(min, max [2]float64, i int) bool {
+				count++
+				return true
+			},
+		)
+		var expect int
+		target := rect[float64]{[2]float64{-90, -45}, [2]float64{90, 45}}
+		for i := 0; i < N; i++ {
+			if target.intersects(&rect[float64]{mins[i], maxs[i]}) {
+				expect++
+			}
+		}
+		if count != expect {
+			t.Fatalf("expected %d, got %d", expect, count)
+		}
+		// loading into a non-empty tree
+		tr1.Load(mins[:N/2], maxs[:N/2], items[:N/2])
+		for i := 0; i < N; i++ {
+			tr1.Delete(mins[i], maxs[i], items[i])
+		}
+		if tr1.Len() != N/2 {
+			t.Fatalf("expected %d, got %d", N/2, tr1.Len())
+		}
+		if err := rSane(&tr1); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
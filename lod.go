@@ -0,0 +1,97 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+// lodEntry is an item, or a node summary, in a level-of-detail search.
+type lodEntry[N numeric, T any] struct {
+	rect rect[N]
+	node *node[N, T] // nil for items
+	data T
+}
+
+// SearchLOD is a level-of-detail search, which returns up to maxResults
+// results for the rectangle, where each result is either an item or a summary
+// of a node. This gives an overview of where the data is, at the detail of
+// the tree itself, without visiting every item.
+//
+// The search starts with the root as one summary, and then expands summaries
+// into their intersecting children, one level at a time, as long as the
+// results still fit in maxResults. For items, the iter function is called
+// with item true and a count of one. For summaries, it's called with the
+// node rect, item false, and the number of items in the node, which includes
+// the items that are outside of the search rectangle.
+func (tr *RTreeGN[N, T]) SearchLOD(min, max [2]N, maxResults int,
+	iter func(min, max [2]N, data T, item bool, count int) bool,
+) {
+	target := rect[N]{min, max}
+	if tr.root == nil || maxResults < 1 || !target.intersects(&tr.rect) {
+		return
+	}
+	list := []lodEntry[N, T]{{rect: tr.rect, node: tr.root}}
+	var next []lodEntry[N, T]
+	for expanded := true; expanded; {
+		expanded = false
+		total := len(list)
+		next = next[:0]
+		for _, e := range list {
+			if e.node == nil {
+				next = append(next, e)
+				continue
+			}
+			rects := e.node.rects[:e.node.count]
+			var k int
+			for i := range rects {
+				if target.intersects(&rects[i]) {
+					k++
+				}
+			}
+			if total-1+k > maxResults {
+				next = append(next, e)
+				continue
+			}
+			total += k - 1
+			expanded = true
+			for i := range rects {
+				if !target.intersects(&rects[i]) {
+					continue
+				}
+				if e.node.leaf() {
+					next = append(next, lodEntry[N, T]{
+						rect: rects[i], data: e.node.items()[i],
+					})
+				} else {
+					next = append(next, lodEntry[N, T]{
+						rect: rects[i], node: e.node.children()[i],
+					})
+				}
+			}
+		}
+		list, next = next, list
+	}
+	for _, e := range list {
+		var ok bool
+		if e.node == nil {
+			ok = iter(e.rect.min, e.rect.max, e.data, true, 1)
+		} else {
+			count := tr.count
+			if e.node != tr.root {
+				count = e.node.deepCount()
+			}
+			ok = iter(e.rect.min, e.rect.max, tr.empty, false, count)
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+// SearchLOD is a level-of-detail search, which returns up to maxResults
+// results for the rectangle, where each result is either an item or a summary
+// of a node with its count of items.
+func (tr *RTreeG[T]) SearchLOD(min, max [2]float64, maxResults int,
+	iter func(min, max [2]float64, data T, item bool, count int) bool,
+) {
+	tr.base.SearchLOD(min, max, maxResults, iter)
+}
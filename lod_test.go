@@ -0,0 +1,71 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSearchLOD(t *testing.T) {
+	rand.Seed(seed)
+	var tr RTreeG[testPoint]
+	N := 50_000
+	for i := 0; i < N; i++ {
+		pt := randTestPoint()
+		tr.Insert(pt.coord(), pt.coord(), pt)
+	}
+	lod := func(min, max [2]float64, maxResults int) (results, items,
+		count int,
+	) {
+		tr.SearchLOD(min, max, maxResults,
+			func(rmin, rmax [2]float64, _ testPoint, item bool, n int) bool {
+				r := rect[float64]{rmin, rmax}
+				if !r.intersects(&rect[float64]{min, max}) {
+					t.Fatalf("result does not intersect")
+				}
+				if item {
+					items++
+				}
+				results++
+				count += n
+				return true
+			},
+		)
+		return results, items, count
+	}
+	min, max := [2]float64{-180, -90}, [2]float64{180, 90}
+	if results, items, count := lod(min, max, 1); results != 1 ||
+		items != 0 || count != N {
+		t.Fatalf("expected the root summary, got %d %d %d", results, items,
+			count)
+	}
+	for _, budget := range []int{10, 100, 1000, 10_000} {
+		results, items, count := lod(min, max, budget)
+		if results > budget || results < budget/64 || count != N {
+			t.Fatalf("budget %d: got %d results, %d items, count %d", budget,
+				results, items, count)
+		}
+	}
+	if results, items, count := lod(min, max, N); results != N ||
+		items != N || count != N {
+		t.Fatalf("expected all items, got %d %d %d", results, items, count)
+	}
+
+	// summaries count the items that are in the window, and more
+	min, max = [2]float64{-50, -20}, [2]float64{30, 40}
+	var expect int
+	tr.Search(min, max, func(_, _ [2]float64, _ testPoint) bool {
+		expect++
+		return true
+	})
+	for _, budget := range []int{1, 50, 500} {
+		results, _, count := lod(min, max, budget)
+		if results > budget || count < expect {
+			t.Fatalf("budget %d: got %d results, count %d", budget, results,
+				count)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import "sync"
+
+// NearbyMany returns the k nearest items to each of the provided points,
+// using the box distance. The iter function is called with the index of the
+// point and the items for each point are returned from the smallest distance
+// to the largest. Returning false stops the results for that point only.
+//
+// The points are processed in spatial order, and the k items found for one
+// point are used to bound the search for the next point, which lets whole
+// subtrees be skipped when neighboring points are near each other.
+func (tr *RTreeGN[N, T]) NearbyMany(points [][2]N, k int,
+	iter func(pointIdx int, min, max [2]N, data T, dist N) bool,
+) {
+	if tr.root == nil || len(points) == 0 || k < 1 {
+		return
+	}
+	tr.nearbyMany(points, hilbertOrder(points, points), k, iter)
+}
+
+// ParallelNearbyMany is like NearbyMany but spreads the points over multiple
+// goroutines. The iter function is called concurrently and must be safe for
+// concurrent use. The items for each point are still returned in order.
+// A workers value of zero or less uses GOMAXPROCS.
+func (tr *RTreeGN[N, T]) ParallelNearbyMany(points [][2]N, k int, workers int,
+	iter func(pointIdx int, min, max [2]N, data T, dist N) bool,
+) {
+	if tr.root == nil || len(points) == 0 || k < 1 {
+		return
+	}
+	keys := hilbertOrder(points, points)
+	workers = parallelWorkers(workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		// each worker gets a run of neighboring points
+		chunk := keys[len(keys)*i/workers : len(keys)*(i+1)/workers]
+		if len(chunk) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tr.nearbyMany(points, chunk, k, iter)
+		}()
+	}
+	wg.Wait()
+}
+
+func (tr *RTreeGN[N, T]) nearbyMany(points [][2]N, keys []lkey[uint32], k int,
+	iter func(pointIdx int, min, max [2]N, data T, dist N) bool,
+) {
+	q := tr.qpool.Get().(*queue[N, N, T])
+	defer func() {
+		*q = (*q)[:0]
+		tr.qpool.Put(q)
+	}()
+	var prev, found []rect[N]
+	for _, key := range keys {
+		target := rect[N]{points[key.idx], points[key.idx]}
+		// The k items found for the previous point are at most this far from
+		// this point, so anything further away can be skipped.
+		var bound N
+		bounded := len(prev) == k
+		for i := range prev {
+			bound = fmax(bound, target.boxDist(&prev[i]))
+		}
+		*q = (*q)[:0]
+		found = found[:0]
+		q.push(qnode[N, N, T]{rect: tr.rect, node: tr.root})
+		for len(found) < k {
+			qn, ok := q.pop()
+			if !ok {
+				break
+			}
+			if qn.node == nil {
+				found = append(found, qn.rect)
+				if !iter(key.idx, qn.rect.min, qn.rect.max, qn.data, qn.dist) {
+					break
+				}
+				continue
+			}
+			rects := qn.node.rects[:qn.node.count]
+			var items []T
+			var children []*node[N, T]
+			if qn.node.leaf() {
+				items = qn.node.items()
+			} else {
+				children = qn.node.children()
+			}
+			for i := 0; i < len(rects); i++ {
+				dist := target.boxDist(&rects[i])
+				if bounded && dist > bound {
+					continue
+				}
+				qn := qnode[N, N, T]{dist: dist, rect: rects[i]}
+				if items != nil {
+					qn.data = items[i]
+				} else {
+					qn.node = children[i]
+				}
+				q.push(qn)
+			}
+		}
+		prev, found = found, prev
+	}
+}
+
+// NearbyMany returns the k nearest items to each of the provided points,
+// using the box distance. The iter function is called with the index of the
+// point and the items for each point are returned from the smallest distance
+// to the largest. Returning false stops the results for that point only.
+func (tr *RTreeG[T]) NearbyMany(points [][2]float64, k int,
+	iter func(pointIdx int, min, max [2]float64, data T, dist float64) bool,
+) {
+	tr.base.NearbyMany(points, k, iter)
+}
+
+// ParallelNearbyMany is like NearbyMany but spreads the points over multiple
+// goroutines. The iter function is called concurrently and must be safe for
+// concurrent use.
+func (tr *RTreeG[T]) ParallelNearbyMany(points [][2]float64, k int,
+	workers int,
+	iter func(pointIdx int, min, max [2]float64, data T, dist float64) bool,
+) {
+	tr.base.ParallelNearbyMany(points, k, workers, iter)
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestNearbyMany(t *testing.T) {
+	rand.Seed(seed)
+	var tr RTreeG[int]
+	for i := 0; i < 20_000; i++ {
+		r := randRect('m')
+		tr.Insert(r.min, r.max, i)
+	}
+	const K = 10
+	points := make([][2]float64, 2000)
+	for i := range points {
+		if i > 0 && i%2 == 0 {
+			// neighbors
+			points[i] = [2]float64{points[i-1][0] + 0.1, points[i-1][1]}
+		} else {
+			points[i] = randRect('p').min
+		}
+	}
+	expect := make([][]float64, len(points))
+	for i, pt := range points {
+		tr.Nearby(BoxDist[float64, int](pt, pt, nil),
+			func(min, max [2]float64, data int, dist float64) bool {
+				expect[i] = append(expect[i], dist)
+				return len(expect[i]) < K
+			},
+		)
+	}
+	check := func(got [][]float64) {
+		for i := range points {
+			if len(got[i]) != K {
+				t.Fatalf("point %d: expected %d, got %d", i, K, len(got[i]))
+			}
+			for j := range got[i] {
+				if got[i][j] != expect[i][j] {
+					t.Fatalf("point %d: expected %v, got %v", i, expect[i],
+						got[i])
+				}
+			}
+		}
+	}
+	got := make([][]float64, len(points))
+	tr.NearbyMany(points, K,
+		func(p int, min, max [2]float64, data int, dist float64) bool {
+			got[p] = append(got[p], dist)
+			return true
+		},
+	)
+	check(got)
+	var mu sync.Mutex
+	got = make([][]float64, len(points))
+	tr.ParallelNearbyMany(points, K, 4,
+		func(p int, min, max [2]float64, data int, dist float64) bool {
+			mu.Lock()
+			got[p] = append(got[p], dist)
+			mu.Unlock()
+			return true
+		},
+	)
+	check(got)
+}
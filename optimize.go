@@ -0,0 +1,132 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+// Optimize rebuilds the tree using the same packing as Load, which removes
+// the node overlap and the empty node space that builds up over time from
+// many inserts, deletes, and replaces.
+//
+// The nodes of the old tree are not modified, so trees that were created with
+// Copy are not affected.
+func (tr *RTreeGN[N, T]) Optimize() {
+	if tr.root == nil {
+		return
+	}
+	rects := make([]rect[N], 0, tr.count)
+	items := make([]T, 0, tr.count)
+	var exps []int64
+	if tr.root.hasexps() {
+		exps = make([]int64, 0, tr.count)
+	}
+	tr.root.collect(&rects, &items, &exps)
+	old := tr.root
+	tr.mods++
+	tr.root, tr.rect = tr.pack(rects, items, exps, 1)
+	tr.unref(old)
+}
+
+// OptimizeIfNeeded calls Optimize when the tree has degraded by more than the
+// provided threshold, and returns true if the tree was optimized.
+//
+// The degradation is the largest of the overlap ratio, which is the total
+// area where sibling leaves overlap divided by the total area of the leaves,
+// and the dead space ratio, which is the fraction of unused leaf entries.
+// Both are in the range 0 to 1, and a newly loaded tree is close to zero.
+func (tr *RTreeGN[N, T]) OptimizeIfNeeded(threshold float64) bool {
+	if tr.root == nil {
+		return false
+	}
+	overlap, dead := tr.degradation()
+	if overlap <= threshold && dead <= threshold {
+		return false
+	}
+	tr.Optimize()
+	return true
+}
+
+// degradation returns the overlap and dead space ratios of the leaves.
+// The overlap of the upper levels is not counted, because packing the leaves
+// with Sort-Tile-Recursive leaves them with little overlap but not the
+// branches above them.
+func (tr *RTreeGN[N, T]) degradation() (overlap, dead float64) {
+	var overlapArea, area float64
+	var leaves int
+	var walk func(n *node[N, T])
+	walk = func(n *node[N, T]) {
+		if n.leaf() {
+			leaves++
+			return
+		}
+		rects := n.rects[:n.count]
+		for i := range rects {
+			if n.children()[i].leaf() {
+				area += float64(rects[i].area())
+				for j := i + 1; j < len(rects); j++ {
+					overlapArea += rects[i].overlapArea(&rects[j])
+				}
+			}
+			walk(n.children()[i])
+		}
+	}
+	walk(tr.root)
+	if area > 0 {
+		overlap = overlapArea / area
+	}
+	dead = 1 - float64(tr.count)/float64(leaves*maxEntries)
+	return overlap, dead
+}
+
+// overlapArea returns the area where both rects intersect.
+func (r *rect[N]) overlapArea(b *rect[N]) float64 {
+	w := float64(fmin(r.max[0], b.max[0])) - float64(fmax(r.min[0], b.min[0]))
+	h := float64(fmin(r.max[1], b.max[1])) - float64(fmax(r.min[1], b.min[1]))
+	if w <= 0 || h <= 0 {
+		return 0
+	}
+	return w * h
+}
+
+// hasexps returns true if any node in the tree has expirations.
+func (n *node[N, T]) hasexps() bool {
+	if n.leaf() {
+		return n.exps != nil
+	}
+	for _, child := range n.children()[:n.count] {
+		if child.hasexps() {
+			return true
+		}
+	}
+	return false
+}
+
+// collect appends all items, and their expirations, to the slices.
+// The exps slice is ignored when nil.
+func (n *node[N, T]) collect(rects *[]rect[N], items *[]T, exps *[]int64) {
+	if n.leaf() {
+		*rects = append(*rects, n.rects[:n.count]...)
+		*items = append(*items, n.items()[:n.count]...)
+		if *exps != nil {
+			for i := 0; i < int(n.count); i++ {
+				*exps = append(*exps, n.exp(i))
+			}
+		}
+		return
+	}
+	for _, child := range n.children()[:n.count] {
+		child.collect(rects, items, exps)
+	}
+}
+
+// Optimize rebuilds the tree using the same packing as Load.
+// Trees that were created with Copy are not affected.
+func (tr *RTreeG[T]) Optimize() {
+	tr.base.Optimize()
+}
+
+// OptimizeIfNeeded calls Optimize when the tree has degraded by more than the
+// provided threshold, and returns true if the tree was optimized.
+func (tr *RTreeG[T]) OptimizeIfNeeded(threshold float64) bool {
+	return tr.base.OptimizeIfNeeded(threshold)
+}
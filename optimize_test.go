@@ -0,0 +1,64 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestOptimize(t *testing.T) {
+	rand.Seed(seed)
+	N := 50_000
+	pts := make([]testPoint, N)
+	tr := new(RTreeG[testPoint])
+	tr.UseArena(0)
+	start := time.Unix(1000, 0)
+	for i := range pts {
+		pts[i] = randTestPoint()
+		var exp time.Time
+		if i%2 == 0 {
+			exp = start
+		}
+		tr.InsertExpires(pts[i].coord(), pts[i].coord(), pts[i], exp)
+	}
+	// churn
+	for i := range pts {
+		pt := pts[i]
+		pts[i].x += rand.Float64() - 0.5
+		pts[i].y += rand.Float64() - 0.5
+		tr.Replace(pt.coord(), pt.coord(), pt, pts[i].coord(), pts[i].coord(),
+			pts[i])
+	}
+	snapshot := tr.Copy()
+	overlap, dead := tr.base.degradation()
+	if tr.OptimizeIfNeeded(1) {
+		t.Fatal("expected no optimize")
+	}
+	if !tr.OptimizeIfNeeded(0.1) {
+		t.Fatalf("expected optimize (overlap %f, dead %f)", overlap, dead)
+	}
+	overlap2, dead2 := tr.base.degradation()
+	if overlap2 >= overlap || dead2 >= dead {
+		t.Fatalf("expected less degradation (overlap %f -> %f, dead %f -> %f)",
+			overlap, overlap2, dead, dead2)
+	}
+	if err := rSane(tr); err != nil {
+		t.Fatal(err)
+	}
+	treePointsMatch(tr, pts)
+	treePointsMatch(snapshot, pts)
+	if err := rSane(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	// the expirations are kept
+	if n := tr.Sweep(start); n != N/2 {
+		t.Fatalf("expected %d, got %d", N/2, n)
+	}
+	if err := rSane(tr); err != nil {
+		t.Fatal(err)
+	}
+}
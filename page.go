@@ -0,0 +1,195 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"unsafe"
+)
+
+// Item is an item in the tree.
+type Item[N numeric, T any] struct {
+	Min  [2]N
+	Max  [2]N
+	Data T
+}
+
+// Cursor is the position of the next page for SearchPage. It's an opaque
+// URL-safe string, and the empty string is the first page.
+type Cursor string
+
+var (
+	// ErrInvalidCursor is returned by SearchPage when the cursor is malformed
+	// or was created for a different rectangle.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrStaleCursor is returned by SearchPage when the tree was modified, or
+	// copied, after the cursor was created, or when the cursor is from a
+	// different tree.
+	ErrStaleCursor = errors.New("stale cursor: tree was modified")
+)
+
+// SearchPage returns up to limit items that intersect the provided rectangle,
+// starting at the cursor, and the cursor of the next page. The next cursor is
+// empty when there are no more items. A limit of zero or less returns all of
+// the remaining items.
+//
+// Paging through a search returns the same items, in the same order, as
+// Search. Each page starts where the last one stopped, so listing all pages
+// costs the same as one Search.
+//
+// The cursor records the tree, the rectangle, and the path to the next item
+// through the tree, and is only valid as long as the tree isn't modified.
+// Using a cursor after any write, after calling Copy, or with another tree
+// returns ErrStaleCursor, and using it with another rectangle returns
+// ErrInvalidCursor. To page through a tree that is being written to, page
+// through a Copy of it instead.
+func (tr *RTreeGN[N, T]) SearchPage(min, max [2]N, limit int, cursor Cursor,
+) (items []Item[N, T], next Cursor, err error) {
+	target := rect[N]{min, max}
+	var resume []int
+	if cursor != "" {
+		icow, mods, window, path, ok := decodeCursor(cursor)
+		if !ok {
+			return nil, "", ErrInvalidCursor
+		}
+		if icow != tr.icow || mods != tr.mods {
+			return nil, "", ErrStaleCursor
+		}
+		if window != target.hash() || !tr.validPath(path) {
+			return nil, "", ErrInvalidCursor
+		}
+		resume = path
+	}
+	if tr.root == nil || !target.intersects(&tr.rect) {
+		return nil, "", nil
+	}
+	if limit <= 0 {
+		limit = -1
+	}
+	p := pager[N, T]{target: target, limit: limit}
+	if p.search(tr.root, resume) {
+		return p.items, "", nil
+	}
+	// the path was appended from the leaf up
+	for i, j := 0, len(p.path)-1; i < j; i, j = i+1, j-1 {
+		p.path[i], p.path[j] = p.path[j], p.path[i]
+	}
+	return p.items, encodeCursor(tr.icow, tr.mods, target.hash(), p.path), nil
+}
+
+type pager[N numeric, T any] struct {
+	target rect[N]
+	limit  int
+	items  []Item[N, T]
+	path   []int
+}
+
+// search the node, starting at the resume path, and returns false when the
+// limit was reached.
+func (p *pager[N, T]) search(n *node[N, T], resume []int) bool {
+	var i int
+	if len(resume) > 0 {
+		i, resume = resume[0], resume[1:]
+	}
+	rects := n.rects[:n.count]
+	for ; i < len(rects); i++ {
+		if !p.target.intersects(&rects[i]) {
+			resume = nil
+			continue
+		}
+		if n.leaf() {
+			if len(p.items) == p.limit {
+				p.path = append(p.path, i)
+				return false
+			}
+			p.items = append(p.items, Item[N, T]{
+				Min: rects[i].min, Max: rects[i].max, Data: n.items()[i],
+			})
+		} else if !p.search(n.children()[i], resume) {
+			p.path = append(p.path, i)
+			return false
+		}
+		resume = nil
+	}
+	return true
+}
+
+// validPath returns true if the path leads to an entry in a leaf.
+func (tr *RTreeGN[N, T]) validPath(path []int) bool {
+	n := tr.root
+	for i, idx := range path {
+		if n == nil || idx < 0 || idx >= int(n.count) {
+			return false
+		}
+		if n.leaf() {
+			return i == len(path)-1
+		}
+		n = n.children()[idx]
+	}
+	return false
+}
+
+// hash returns the FNV-1a hash of the rect, which a cursor records to check
+// that it's used with the same rect.
+func (r *rect[N]) hash() uint32 {
+	h := fnv.New32a()
+	h.Write(unsafe.Slice((*byte)(unsafe.Pointer(r)), unsafe.Sizeof(*r)))
+	return h.Sum32()
+}
+
+func encodeCursor(icow, mods uint64, window uint32, path []int) Cursor {
+	buf := make([]byte, 0, 24+len(path))
+	var tmp [binary.MaxVarintLen64]byte
+	buf = append(buf, tmp[:binary.PutUvarint(tmp[:], icow)]...)
+	buf = append(buf, tmp[:binary.PutUvarint(tmp[:], mods)]...)
+	binary.LittleEndian.PutUint32(tmp[:], window)
+	buf = append(buf, tmp[:4]...)
+	for _, idx := range path {
+		// an index is always less than maxEntries
+		buf = append(buf, byte(idx))
+	}
+	return Cursor(base64.RawURLEncoding.EncodeToString(buf))
+}
+
+func decodeCursor(cursor Cursor) (icow, mods uint64, window uint32,
+	path []int, ok bool,
+) {
+	buf, err := base64.RawURLEncoding.DecodeString(string(cursor))
+	if err != nil {
+		return 0, 0, 0, nil, false
+	}
+	var n int
+	if icow, n = binary.Uvarint(buf); n <= 0 {
+		return 0, 0, 0, nil, false
+	}
+	buf = buf[n:]
+	if mods, n = binary.Uvarint(buf); n <= 0 {
+		return 0, 0, 0, nil, false
+	}
+	buf = buf[n:]
+	if len(buf) <= 4 {
+		return 0, 0, 0, nil, false
+	}
+	window = binary.LittleEndian.Uint32(buf)
+	buf = buf[4:]
+	path = make([]int, len(buf))
+	for i := range buf {
+		path[i] = int(buf[i])
+	}
+	return icow, mods, window, path, true
+}
+
+// SearchPage returns up to limit items that intersect the provided rectangle,
+// starting at the cursor, and the cursor of the next page. The next cursor is
+// empty when there are no more items.
+// The cursor is only valid as long as the tree isn't modified.
+func (tr *RTreeG[T]) SearchPage(min, max [2]float64, limit int,
+	cursor Cursor,
+) (items []Item[float64, T], next Cursor, err error) {
+	return tr.base.SearchPage(min, max, limit, cursor)
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSearchPage(t *testing.T) {
+	rand.Seed(seed)
+	var tr RTreeG[testPoint]
+	if items, next, err := tr.SearchPage([2]float64{-180, -90},
+		[2]float64{180, 90}, 10, ""); len(items) != 0 || next != "" ||
+		err != nil {
+		t.Fatalf("expected no items")
+	}
+	for i := 0; i < 20_000; i++ {
+		pt := randTestPoint()
+		tr.Insert(pt.coord(), pt.coord(), pt)
+	}
+	windows := [][2][2]float64{
+		{{-180, -90}, {180, 90}},
+		{{-50, -20}, {30, 40}},
+		{{10, 10}, {10, 10}},
+	}
+	for _, w := range windows {
+		var expect []testPoint
+		tr.Search(w[0], w[1], func(_, _ [2]float64, pt testPoint) bool {
+			expect = append(expect, pt)
+			return true
+		})
+		for _, limit := range []int{1, 7, 500, len(expect), 0} {
+			var all []testPoint
+			var cursor Cursor
+			for {
+				items, next, err := tr.SearchPage(w[0], w[1], limit, cursor)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if limit > 0 && len(items) > limit {
+					t.Fatalf("expected at most %d items, got %d", limit,
+						len(items))
+				}
+				if next != "" && len(items) == 0 {
+					t.Fatalf("expected items with a next cursor")
+				}
+				for _, item := range items {
+					all = append(all, item.Data)
+				}
+				if next == "" {
+					break
+				}
+				cursor = next
+			}
+			if len(all) != len(expect) {
+				t.Fatalf("limit %d: expected %d items, got %d", limit,
+					len(expect), len(all))
+			}
+			for i := range all {
+				if all[i] != expect[i] {
+					t.Fatalf("limit %d: item %d is out of order", limit, i)
+				}
+			}
+		}
+	}
+
+	_, cursor, err := tr.SearchPage(windows[0][0], windows[0][1], 10, "")
+	if err != nil || cursor == "" {
+		t.Fatalf("expected a cursor")
+	}
+	for _, bad := range []Cursor{"!", "AA", cursor + "AAAA"} {
+		if _, _, err := tr.SearchPage(windows[0][0], windows[0][1], 10,
+			bad); err != ErrInvalidCursor {
+			t.Fatalf("expected ErrInvalidCursor for %q, got %v", bad, err)
+		}
+	}
+	// the cursor only works with its own rectangle
+	if _, _, err := tr.SearchPage(windows[1][0], windows[1][1], 10,
+		cursor); err != ErrInvalidCursor {
+		t.Fatalf("expected ErrInvalidCursor, got %v", err)
+	}
+	// and its own tree, even one with the same number of writes
+	var other RTreeG[testPoint]
+	tr.Scan(func(min, max [2]float64, pt testPoint) bool {
+		other.Insert(min, max, pt)
+		return true
+	})
+	if other.base.mods != tr.base.mods {
+		t.Fatalf("expected the same number of writes")
+	}
+	if _, _, err := other.SearchPage(windows[0][0], windows[0][1], 10,
+		cursor); err != ErrStaleCursor {
+		t.Fatalf("expected ErrStaleCursor, got %v", err)
+	}
+	// copies and writes make the cursor stale
+	tr2 := tr.Copy()
+	if _, _, err := tr.SearchPage(windows[0][0], windows[0][1], 10,
+		cursor); err != ErrStaleCursor {
+		t.Fatalf("expected ErrStaleCursor, got %v", err)
+	}
+	_, cursor, _ = tr2.SearchPage(windows[0][0], windows[0][1], 10, "")
+	pt := randTestPoint()
+	tr2.Insert(pt.coord(), pt.coord(), pt)
+	if _, _, err := tr2.SearchPage(windows[0][0], windows[0][1], 10,
+		cursor); err != ErrStaleCursor {
+		t.Fatalf("expected ErrStaleCursor, got %v", err)
+	}
+}
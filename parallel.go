@@ -0,0 +1,171 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// subtrees returns the nodes that intersect the target, from the highest
+// level that has at least count nodes, or from the leaf level.
+// The nodes are returned in the same order that Search visits them.
+func (tr *RTreeGN[N, T]) subtrees(target rect[N], count int) []*node[N, T] {
+	if tr.root == nil || !target.intersects(&tr.rect) {
+		return nil
+	}
+	nodes := []*node[N, T]{tr.root}
+	for len(nodes) < count && !nodes[0].leaf() {
+		var next []*node[N, T]
+		for _, n := range nodes {
+			rects := n.rects[:n.count]
+			children := n.children()
+			for i := 0; i < len(rects); i++ {
+				if target.intersects(&rects[i]) {
+					next = append(next, children[i])
+				}
+			}
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func parallelWorkers(workers int) int {
+	if workers < 1 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return workers
+}
+
+// ParallelSearch searches for items in tree that intersect the provided
+// rectangle using multiple goroutines. Each goroutine searches different
+// subtrees.
+//
+// The iter function is called concurrently and must be safe for concurrent
+// use. Items are delivered in no specific order. Returning false stops the
+// search, but calls that other goroutines have already started will still
+// finish. A workers value of zero or less uses GOMAXPROCS.
+func (tr *RTreeGN[N, T]) ParallelSearch(min, max [2]N, workers int,
+	iter func(min, max [2]N, data T) bool,
+) {
+	workers = parallelWorkers(workers)
+	target := rect[N]{min, max}
+	tasks := tr.subtrees(target, workers*4)
+	if len(tasks) == 0 {
+		return
+	}
+	var next int64
+	var stop int32
+	var wg sync.WaitGroup
+	for i := 0; i < workers && i < len(tasks); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for atomic.LoadInt32(&stop) == 0 {
+				i := int(atomic.AddInt64(&next, 1) - 1)
+				if i >= len(tasks) {
+					return
+				}
+				tasks[i].search(target, func(min, max [2]N, data T) bool {
+					if atomic.LoadInt32(&stop) != 0 {
+						return false
+					}
+					if !iter(min, max, data) {
+						atomic.StoreInt32(&stop, 1)
+						return false
+					}
+					return true
+				})
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+type presult[N numeric, T any] struct {
+	done  chan struct{}
+	rects []rect[N]
+	items []T
+}
+
+// ParallelSearchOrdered searches for items in tree that intersect the provided
+// rectangle using multiple goroutines. Each goroutine collects the items from
+// different subtrees, and the results are merged so that the iter function is
+// called from a single goroutine and in the same order as Search.
+// A workers value of zero or less uses GOMAXPROCS.
+func (tr *RTreeGN[N, T]) ParallelSearchOrdered(min, max [2]N, workers int,
+	iter func(min, max [2]N, data T) bool,
+) {
+	workers = parallelWorkers(workers)
+	target := rect[N]{min, max}
+	tasks := tr.subtrees(target, workers*4)
+	if len(tasks) == 0 {
+		return
+	}
+	results := make([]presult[N, T], len(tasks))
+	for i := range results {
+		results[i].done = make(chan struct{})
+	}
+	var next int64
+	var stop int32
+	var wg sync.WaitGroup
+	for i := 0; i < workers && i < len(tasks); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1) - 1)
+				if i >= len(tasks) {
+					return
+				}
+				res := &results[i]
+				if atomic.LoadInt32(&stop) == 0 {
+					tasks[i].search(target, func(min, max [2]N, data T) bool {
+						res.rects = append(res.rects, rect[N]{min, max})
+						res.items = append(res.items, data)
+						return true
+					})
+				}
+				close(res.done)
+			}
+		}()
+	}
+	defer wg.Wait()
+	for i := range results {
+		<-results[i].done
+		for j, r := range results[i].rects {
+			if !iter(r.min, r.max, results[i].items[j]) {
+				atomic.StoreInt32(&stop, 1)
+				return
+			}
+		}
+		results[i] = presult[N, T]{}
+	}
+}
+
+// ParallelSearch searches for items in tree that intersect the provided
+// rectangle using multiple goroutines.
+// The iter function is called concurrently and must be safe for concurrent
+// use. Items are delivered in no specific order.
+func (tr *RTreeG[T]) ParallelSearch(min, max [2]float64, workers int,
+	iter func(min, max [2]float64, data T) bool,
+) {
+	tr.base.ParallelSearch(min, max, workers, iter)
+}
+
+// ParallelSearchOrdered searches for items in tree that intersect the provided
+// rectangle using multiple goroutines.
+// The iter function is called from a single goroutine and in the same order
+// as Search.
+func (tr *RTreeG[T]) ParallelSearchOrdered(min, max [2]float64, workers int,
+	iter func(min, max [2]float64, data T) bool,
+) {
+	tr.base.ParallelSearchOrdered(min, max, workers, iter)
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParallelSearch(t *testing.T) {
+	rand.Seed(seed)
+	N := 100_000
+	var tr RTreeG[int]
+	for i := 0; i < N; i++ {
+		r := randRect('m')
+		tr.Insert(r.min, r.max, i)
+	}
+	min, max := [2]float64{-90, -45}, [2]float64{90, 45}
+	var expect []int
+	tr.Search(min, max, func(min, max [2]float64, i int) bool {
+		expect = append(expect, i)
+		return true
+	})
+	for _, workers := range []int{0, 1, 3, 16} {
+		var count int64
+		tr.ParallelSearch(min, max, workers,
+			func(min, max [2]float64, i int) bool {
+				atomic.AddInt64(&count, 1)
+				return true
+			},
+		)
+		if int(count) != len(expect) {
+			t.Fatalf("expected %d, got %d", len(expect), count)
+		}
+		var ordered []int
+		tr.ParallelSearchOrdered(min, max, workers,
+			func(min, max [2]float64, i int) bool {
+				ordered = append(ordered, i)
+				return true
+			},
+		)
+		if len(ordered) != len(expect) {
+			t.Fatalf("expected %d, got %d", len(expect), len(ordered))
+		}
+		for i := range expect {
+			if ordered[i] != expect[i] {
+				t.Fatalf("out of order at %d", i)
+			}
+		}
+		ordered = ordered[:0]
+		tr.ParallelSearchOrdered(min, max, workers,
+			func(min, max [2]float64, i int) bool {
+				ordered = append(ordered, i)
+				return len(ordered) < 10
+			},
+		)
+		if len(ordered) != 10 {
+			t.Fatalf("expected %d, got %d", 10, len(ordered))
+		}
+	}
+	var empty RTreeG[int]
+	empty.ParallelSearch(min, max, 4, func(min, max [2]float64, i int) bool {
+		t.Fatal("unexpected item")
+		return true
+	})
+}
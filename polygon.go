@@ -0,0 +1,129 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+// polygon is a ring used for searching. The ring is closed automatically.
+type polygon struct {
+	ring  [][2]float64
+	edges []ray
+	rect  rect[float64]
+}
+
+const (
+	polyOutside = iota
+	polyBoundary
+	polyInside
+)
+
+func newPolygon[N numeric](ring [][2]N) *polygon {
+	p := &polygon{
+		ring:  make([][2]float64, len(ring)),
+		edges: make([]ray, len(ring)),
+	}
+	for i := range ring {
+		p.ring[i] = [2]float64{float64(ring[i][0]), float64(ring[i][1])}
+	}
+	p.rect = rect[float64]{p.ring[0], p.ring[0]}
+	for i, a := range p.ring {
+		b := p.ring[(i+1)%len(p.ring)]
+		p.edges[i] = ray{o: a, d: [2]float64{b[0] - a[0], b[1] - a[1]}, tmax: 1}
+		p.rect.expand(&rect[float64]{a, a})
+	}
+	return p
+}
+
+// contains returns true if the point is inside the ring, using the even-odd
+// rule.
+func (p *polygon) contains(pt [2]float64) bool {
+	var in bool
+	for i, j := 0, len(p.ring)-1; i < len(p.ring); j, i = i, i+1 {
+		a, b := p.ring[i], p.ring[j]
+		if (a[1] > pt[1]) != (b[1] > pt[1]) &&
+			pt[0] < (b[0]-a[0])*(pt[1]-a[1])/(b[1]-a[1])+a[0] {
+			in = !in
+		}
+	}
+	return in
+}
+
+// classify returns whether the rect is outside, inside, or crosses the
+// boundary of the polygon. A rect that no edge passes through is either
+// fully inside or fully outside, which is decided by one of its corners.
+func classify[N numeric](p *polygon, r *rect[N]) int {
+	fr := rect[float64]{
+		[2]float64{float64(r.min[0]), float64(r.min[1])},
+		[2]float64{float64(r.max[0]), float64(r.max[1])},
+	}
+	if !fr.intersects(&p.rect) {
+		return polyOutside
+	}
+	for i := range p.edges {
+		if _, ok := enter(&p.edges[i], &fr); ok {
+			return polyBoundary
+		}
+	}
+	if p.contains(fr.min) {
+		return polyInside
+	}
+	return polyOutside
+}
+
+// SearchPolygon searches for items that intersect the polygon ring.
+// The ring is closed automatically and may be in either winding order.
+// Nodes that are fully outside of the polygon are skipped, and nodes that
+// are fully inside return all of their items without checking each one.
+func (tr *RTreeGN[N, T]) SearchPolygon(ring [][2]N,
+	iter func(min, max [2]N, data T) bool,
+) {
+	if tr.root == nil || len(ring) == 0 {
+		return
+	}
+	p := newPolygon(ring)
+	switch classify(p, &tr.rect) {
+	case polyInside:
+		tr.root.scan(iter)
+	case polyBoundary:
+		tr.root.searchPolygon(p, iter)
+	}
+}
+
+func (n *node[N, T]) searchPolygon(p *polygon,
+	iter func(min, max [2]N, data T) bool,
+) bool {
+	rects := n.rects[:n.count]
+	if n.leaf() {
+		items := n.items()
+		for i := range rects {
+			if classify(p, &rects[i]) != polyOutside {
+				if !iter(rects[i].min, rects[i].max, items[i]) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	children := n.children()
+	for i := range rects {
+		switch classify(p, &rects[i]) {
+		case polyInside:
+			if !children[i].scan(iter) {
+				return false
+			}
+		case polyBoundary:
+			if !children[i].searchPolygon(p, iter) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// SearchPolygon searches for items that intersect the polygon ring.
+// The ring is closed automatically and may be in either winding order.
+func (tr *RTreeG[T]) SearchPolygon(ring [][2]float64,
+	iter func(min, max [2]float64, data T) bool,
+) {
+	tr.base.SearchPolygon(ring, iter)
+}
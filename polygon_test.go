@@ -0,0 +1,88 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func segmentsCross(a, b, c, d [2]float64) bool {
+	orient := func(p, q, r [2]float64) float64 {
+		return (q[0]-p[0])*(r[1]-p[1]) - (q[1]-p[1])*(r[0]-p[0])
+	}
+	return orient(a, b, c)*orient(a, b, d) < 0 &&
+		orient(c, d, a)*orient(c, d, b) < 0
+}
+
+func TestSearchPolygon(t *testing.T) {
+	rand.Seed(seed)
+	N := 20_000
+	rects := make([]rect[float64], N)
+	var tr RTreeG[int]
+	for i := range rects {
+		x, y := rand.Float64()*1000, rand.Float64()*1000
+		rects[i] = rect[float64]{
+			[2]float64{x, y},
+			[2]float64{x + rand.Float64()*10, y + rand.Float64()*10},
+		}
+		tr.Insert(rects[i].min, rects[i].max, i)
+	}
+	for k := 0; k < 50; k++ {
+		// a random star shaped lasso
+		cx, cy := rand.Float64()*1000, rand.Float64()*1000
+		n := 3 + rand.Intn(30)
+		ring := make([][2]float64, n)
+		for i := range ring {
+			a := float64(i) / float64(n) * 2 * math.Pi
+			d := 20 + rand.Float64()*300
+			ring[i] = [2]float64{cx + math.Cos(a)*d, cy + math.Sin(a)*d}
+		}
+		p := newPolygon(ring)
+		expect := make(map[int]bool)
+		for i, r := range rects {
+			corners := [][2]float64{r.min, {r.max[0], r.min[1]}, r.max,
+				{r.min[0], r.max[1]}}
+			var hit bool
+			for j := 0; j < 4 && !hit; j++ {
+				hit = p.contains(corners[j])
+				for e := 0; e < n && !hit; e++ {
+					hit = segmentsCross(corners[j], corners[(j+1)%4],
+						ring[e], ring[(e+1)%n])
+				}
+			}
+			for e := 0; e < n && !hit; e++ {
+				hit = r.contains(&rect[float64]{ring[e], ring[e]})
+			}
+			if hit {
+				expect[i] = true
+			}
+		}
+		var count int
+		tr.SearchPolygon(ring, func(_, _ [2]float64, i int) bool {
+			if !expect[i] {
+				t.Fatalf("unexpected item %d", i)
+			}
+			count++
+			return true
+		})
+		if count != len(expect) {
+			t.Fatalf("expected %d items, got %d", len(expect), count)
+		}
+	}
+
+	// a polygon around everything
+	var count int
+	tr.SearchPolygon([][2]float64{{-1, -1}, {2000, -1}, {-1, 2000}},
+		func(_, _ [2]float64, _ int) bool {
+			count++
+			return true
+		},
+	)
+	if count != N {
+		t.Fatalf("expected %d items, got %d", N, count)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+// SearchRadius searches for items that are within the radius of the center
+// point. When toCenter is false the distance is measured to the closest point
+// of the item rect, otherwise it's measured to the center of the item rect.
+// Nodes that are further than the radius are skipped.
+func (tr *RTreeGN[N, T]) SearchRadius(center [2]N, radius N, toCenter bool,
+	iter func(min, max [2]N, data T) bool,
+) {
+	tr.SearchBuffer(center, center, radius, toCenter, iter)
+}
+
+// SearchBuffer searches for items that are within the distance d of the
+// provided rectangle. When toCenter is false the distance is measured to the
+// closest point of the item rect, otherwise it's measured to the center of
+// the item rect. Nodes that are further than the distance are skipped.
+func (tr *RTreeGN[N, T]) SearchBuffer(min, max [2]N, d N, toCenter bool,
+	iter func(min, max [2]N, data T) bool,
+) {
+	if tr.root == nil || d < 0 {
+		return
+	}
+	target := rect[N]{min, max}
+	dd := d * d
+	if target.boxDist(&tr.rect) > dd {
+		return
+	}
+	tr.root.searchWithin(&target, dd, toCenter, iter)
+}
+
+func (n *node[N, T]) searchWithin(target *rect[N], dd N, toCenter bool,
+	iter func(min, max [2]N, data T) bool,
+) bool {
+	rects := n.rects[:n.count]
+	if n.leaf() {
+		items := n.items()
+		for i := range rects {
+			var dist N
+			if toCenter {
+				c := rects[i].center()
+				dist = target.boxDist(&rect[N]{c, c})
+			} else {
+				dist = target.boxDist(&rects[i])
+			}
+			if dist <= dd {
+				if !iter(rects[i].min, rects[i].max, items[i]) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	children := n.children()
+	for i := range rects {
+		if target.boxDist(&rects[i]) <= dd {
+			if !children[i].searchWithin(target, dd, toCenter, iter) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// center returns the center point of the rect.
+func (r *rect[N]) center() [2]N {
+	return [2]N{(r.min[0] + r.max[0]) / 2, (r.min[1] + r.max[1]) / 2}
+}
+
+// SearchRadius searches for items that are within the radius of the center
+// point, measured to the item rect or to its center.
+func (tr *RTreeG[T]) SearchRadius(center [2]float64, radius float64,
+	toCenter bool, iter func(min, max [2]float64, data T) bool,
+) {
+	tr.base.SearchRadius(center, radius, toCenter, iter)
+}
+
+// SearchBuffer searches for items that are within the distance d of the
+// provided rectangle, measured to the item rect or to its center.
+func (tr *RTreeG[T]) SearchBuffer(min, max [2]float64, d float64,
+	toCenter bool, iter func(min, max [2]float64, data T) bool,
+) {
+	tr.base.SearchBuffer(min, max, d, toCenter, iter)
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSearchRadius(t *testing.T) {
+	rand.Seed(seed)
+	N := 20_000
+	rects := make([]rect[float64], N)
+	var tr RTreeG[int]
+	for i := range rects {
+		x, y := rand.Float64()*1000, rand.Float64()*1000
+		rects[i] = rect[float64]{
+			[2]float64{x, y},
+			[2]float64{x + rand.Float64()*10, y + rand.Float64()*10},
+		}
+		tr.Insert(rects[i].min, rects[i].max, i)
+	}
+	for k := 0; k < 200; k++ {
+		x, y := rand.Float64()*1000, rand.Float64()*1000
+		target := rect[float64]{[2]float64{x, y}, [2]float64{x, y}}
+		if k%2 == 1 {
+			target.max = [2]float64{x + rand.Float64()*50, y + rand.Float64()*50}
+		}
+		d := rand.Float64() * 100
+		toCenter := k%4 < 2
+		expect := make(map[int]bool)
+		for i := range rects {
+			r := rects[i]
+			if toCenter {
+				c := r.center()
+				r = rect[float64]{c, c}
+			}
+			if target.boxDist(&r) <= d*d {
+				expect[i] = true
+			}
+		}
+		var count int
+		iter := func(_, _ [2]float64, i int) bool {
+			if !expect[i] {
+				t.Fatalf("unexpected item %d", i)
+			}
+			count++
+			return true
+		}
+		if k%2 == 0 {
+			tr.SearchRadius(target.min, d, toCenter, iter)
+		} else {
+			tr.SearchBuffer(target.min, target.max, d, toCenter, iter)
+		}
+		if count != len(expect) {
+			t.Fatalf("expected %d items, got %d", len(expect), count)
+		}
+	}
+}
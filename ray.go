@@ -0,0 +1,156 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import "math"
+
+// ray is the line o + t*d, for t in the range 0 to tmax.
+type ray struct {
+	o, d [2]float64
+	tmax float64
+}
+
+// enter returns the smallest t where the ray is inside the rect, using the
+// slab test, or false if the ray misses the rect.
+func enter[N numeric](r *ray, b *rect[N]) (float64, bool) {
+	tnear, tfar := 0.0, r.tmax
+	for i := 0; i < 2; i++ {
+		min, max := float64(b.min[i]), float64(b.max[i])
+		if r.d[i] == 0 {
+			if r.o[i] < min || r.o[i] > max {
+				return 0, false
+			}
+			continue
+		}
+		t1 := (min - r.o[i]) / r.d[i]
+		t2 := (max - r.o[i]) / r.d[i]
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		if t1 > tnear {
+			tnear = t1
+		}
+		if t2 < tfar {
+			tfar = t2
+		}
+		if tnear > tfar {
+			return 0, false
+		}
+	}
+	return tnear, true
+}
+
+// SearchSegment searches for items that intersect the line segment from a
+// to b. Nodes that the segment does not pass through are skipped, which is
+// much tighter than a Search over the bounding box of the segment for
+// diagonal lines.
+func (tr *RTreeGN[N, T]) SearchSegment(a, b [2]N,
+	iter func(min, max [2]N, data T) bool,
+) {
+	if tr.root == nil {
+		return
+	}
+	r := ray{
+		o: [2]float64{float64(a[0]), float64(a[1])},
+		d: [2]float64{
+			float64(b[0]) - float64(a[0]),
+			float64(b[1]) - float64(a[1]),
+		},
+		tmax: 1,
+	}
+	if _, ok := enter(&r, &tr.rect); ok {
+		tr.root.searchRay(&r, iter)
+	}
+}
+
+func (n *node[N, T]) searchRay(r *ray, iter func(min, max [2]N, data T) bool,
+) bool {
+	rects := n.rects[:n.count]
+	for i := range rects {
+		if _, ok := enter(r, &rects[i]); !ok {
+			continue
+		}
+		if n.leaf() {
+			if !iter(rects[i].min, rects[i].max, n.items()[i]) {
+				return false
+			}
+		} else if !n.children()[i].searchRay(r, iter) {
+			return false
+		}
+	}
+	return true
+}
+
+// Raycast searches for items that are hit by the ray from origin in the
+// direction of dir. The iter function is called for each hit in order of the
+// entry distance t, where the ray enters the item rect at origin + t*dir.
+// Items that contain the origin have a t of zero. Only hits with a t up to
+// maxT are returned, and a maxT of zero or less is unlimited.
+func (tr *RTreeGN[N, T]) Raycast(origin, dir [2]N, maxT float64,
+	iter func(min, max [2]N, data T, t float64) bool,
+) {
+	if tr.root == nil || (dir[0] == 0 && dir[1] == 0) {
+		return
+	}
+	if maxT <= 0 {
+		maxT = math.Inf(1)
+	}
+	r := ray{
+		o:    [2]float64{float64(origin[0]), float64(origin[1])},
+		d:    [2]float64{float64(dir[0]), float64(dir[1])},
+		tmax: maxT,
+	}
+	t, ok := enter(&r, &tr.rect)
+	if !ok {
+		return
+	}
+	var q queue[float64, N, T]
+	q.push(qnode[float64, N, T]{dist: t, rect: tr.rect, node: tr.root})
+	for {
+		qn, ok := q.pop()
+		if !ok {
+			return
+		}
+		if qn.node == nil {
+			if !iter(qn.rect.min, qn.rect.max, qn.data, qn.dist) {
+				return
+			}
+			continue
+		}
+		rects := qn.node.rects[:qn.node.count]
+		for i := range rects {
+			t, ok := enter(&r, &rects[i])
+			if !ok {
+				continue
+			}
+			if qn.node.leaf() {
+				q.push(qnode[float64, N, T]{
+					dist: t, rect: rects[i], data: qn.node.items()[i],
+				})
+			} else {
+				q.push(qnode[float64, N, T]{
+					dist: t, rect: rects[i], node: qn.node.children()[i],
+				})
+			}
+		}
+	}
+}
+
+// SearchSegment searches for items that intersect the line segment from a
+// to b.
+func (tr *RTreeG[T]) SearchSegment(a, b [2]float64,
+	iter func(min, max [2]float64, data T) bool,
+) {
+	tr.base.SearchSegment(a, b, iter)
+}
+
+// Raycast searches for items that are hit by the ray from origin in the
+// direction of dir, in order of the entry distance t.
+// A maxT of zero or less is unlimited.
+func (tr *RTreeG[T]) Raycast(origin, dir [2]float64, maxT float64,
+	iter func(min, max [2]float64, data T, t float64) bool,
+) {
+	tr.base.Raycast(origin, dir, maxT, iter)
+}
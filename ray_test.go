@@ -0,0 +1,90 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRay(t *testing.T) {
+	rand.Seed(seed)
+	N := 20_000
+	rects := make([]rect[float64], N)
+	var tr RTreeG[int]
+	for i := range rects {
+		x, y := rand.Float64()*1000, rand.Float64()*1000
+		rects[i] = rect[float64]{
+			[2]float64{x, y},
+			[2]float64{x + rand.Float64()*10, y + rand.Float64()*10},
+		}
+		tr.Insert(rects[i].min, rects[i].max, i)
+	}
+	for k := 0; k < 100; k++ {
+		a := [2]float64{rand.Float64() * 1000, rand.Float64() * 1000}
+		b := [2]float64{rand.Float64() * 1000, rand.Float64() * 1000}
+		if k%10 == 0 {
+			// axis aligned
+			b[k%20/10] = a[k%20/10]
+		}
+		r := ray{o: a, d: [2]float64{b[0] - a[0], b[1] - a[1]}, tmax: 1}
+		expect := make(map[int]float64)
+		for i := range rects {
+			if t, ok := enter(&r, &rects[i]); ok {
+				expect[i] = t
+			}
+		}
+		var count int
+		tr.SearchSegment(a, b, func(_, _ [2]float64, i int) bool {
+			if _, ok := expect[i]; !ok {
+				t.Fatalf("unexpected item %d", i)
+			}
+			count++
+			return true
+		})
+		if count != len(expect) {
+			t.Fatalf("expected %d items, got %d", len(expect), count)
+		}
+		if len(expect) == 0 {
+			continue
+		}
+
+		// a ray with a max of one is the same as the segment
+		var last float64
+		count = 0
+		tr.Raycast(a, r.d, 1, func(_, _ [2]float64, i int, d float64) bool {
+			if ed, ok := expect[i]; !ok || ed != d {
+				t.Fatalf("unexpected hit %d", i)
+			}
+			if d < last {
+				t.Fatalf("hits are not in order")
+			}
+			last = d
+			count++
+			return true
+		})
+		if count != len(expect) {
+			t.Fatalf("expected %d hits, got %d", len(expect), count)
+		}
+
+		// an unlimited ray hits at least as much
+		var count2 int
+		tr.Raycast(a, r.d, 0, func(_, _ [2]float64, _ int, _ float64) bool {
+			count2++
+			return true
+		})
+		if count2 < count {
+			t.Fatalf("expected at least %d hits, got %d", count, count2)
+		}
+		count2 = 0
+		tr.Raycast(a, r.d, 0, func(_, _ [2]float64, _ int, _ float64) bool {
+			count2++
+			return false
+		})
+		if count2 != 1 {
+			t.Fatalf("expected to stop after one hit")
+		}
+	}
+}
@@ -51,6 +51,10 @@ type RTreeGN[N numeric, T any] struct {
 	root  *node[N, T]
 	empty T
 	qpool *sync.Pool
+	ipool *sync.Pool
+	watch *watchers[N, T]
+	arena *arena[N, T]
+	mods  uint64 // incremented on every write, see page.go
 }
 
 type rect[N numeric] struct {
@@ -82,10 +86,14 @@ const (
 )
 
 type node[N numeric, T any] struct {
-	icow  uint64
-	kind  kind
-	count int16
-	rects [maxEntries]rect[N]
+	icow   uint64
+	kind   kind
+	pooled bool // allocated from an arena, see arena.go
+	count  int16
+	refs   int32 // references from parents, for pooled nodes only
+	rects  [maxEntries]rect[N]
+	exps   *[maxEntries]int64       // expirations, see expire.go
+	vels   *[maxEntries]velocity[N] // velocities, see tpr.go
 }
 
 func (n *node[N, T]) leaf() bool {
@@ -119,6 +127,9 @@ func (n *node[N, T]) items() []T {
 }
 
 func (tr *RTreeGN[N, T]) newNode(isleaf bool) *node[N, T] {
+	if tr.arena != nil {
+		return tr.arena.alloc(isleaf, tr.icow)
+	}
 	if isleaf {
 		n := &leafNode[N, T]{node: node[N, T]{icow: tr.icow, kind: leaf}}
 		return (*node[N, T])(unsafe.Pointer(n))
@@ -138,28 +149,29 @@ func (n *node[N, T]) rect() rect[N] {
 
 // Insert data into tree
 func (tr *RTreeGN[N, T]) Insert(min, max [2]N, data T) {
+	tr.insert(min, max, data, 0, velocity[N]{})
+	if tr.watch != nil {
+		tr.watch.notify(WatchEvent[N, T]{
+			Op: WatchInsert, NewMin: min, NewMax: max, NewData: data,
+		})
+	}
+}
+
+func (tr *RTreeGN[N, T]) insert(min, max [2]N, data T, exp int64,
+	vel velocity[N],
+) {
+	tr.mods++
 	ir := rect[N]{min, max}
 	if tr.root == nil {
-		if tr.qpool == nil {
-			tr.qpool = &sync.Pool{
-				New: func() any { return &queue[N, T]{} },
-			}
-		}
+		tr.init()
 		tr.root = tr.newNode(true)
 		tr.rect = ir
 	}
 	tr.cow(&tr.root)
-	split, grown := tr.nodeInsert(&tr.rect, tr.root, &ir, data)
+	split, grown := tr.nodeInsert(&tr.rect, tr.root, &ir, data, exp, vel)
 	if split {
-		left := tr.root
-		right := tr.splitNode(tr.rect, left)
-		tr.root = tr.newNode(false)
-		tr.root.rects[0] = left.rect()
-		tr.root.rects[1] = right.rect()
-		tr.root.children()[0] = left
-		tr.root.children()[1] = right
-		tr.root.count = 2
-		tr.Insert(min, max, data)
+		tr.splitRoot()
+		tr.insert(min, max, data, exp, vel)
 		if orderBranches {
 			tr.root.sort()
 		}
@@ -174,6 +186,42 @@ func (tr *RTreeGN[N, T]) Insert(min, max [2]N, data T) {
 	tr.count++
 }
 
+// init prepares the tree for its first items.
+func (tr *RTreeGN[N, T]) init() {
+	if tr.icow == 0 {
+		// Every tree gets its own copy-on-write id, which also identifies
+		// the tree to a SearchPage cursor.
+		tr.icow = atomic.AddUint64(&gcow, 1)
+	}
+	if tr.qpool == nil {
+		tr.qpool = &sync.Pool{
+			New: func() any { return &queue[N, N, T]{} },
+		}
+	}
+	if tr.ipool == nil {
+		tr.ipool = &sync.Pool{
+			New: func() any { return &Iterator[N, T]{} },
+		}
+	}
+}
+
+// splitRoot splits the root into two nodes, which become the children of a
+// new root.
+func (tr *RTreeGN[N, T]) splitRoot() {
+	left := tr.root
+	right := tr.splitNode(tr.rect, left)
+	tr.root = tr.newNode(false)
+	tr.root.rects[0] = left.rect()
+	tr.root.rects[1] = right.rect()
+	tr.root.children()[0] = left
+	tr.root.children()[1] = right
+	tr.root.setexp(0, left.minexp())
+	tr.root.setexp(1, right.minexp())
+	tr.root.setvel(0, left.velbounds())
+	tr.root.setvel(1, right.velbounds())
+	tr.root.count = 2
+}
+
 func (tr *RTreeGN[N, T]) splitNode(r rect[N], left *node[N, T],
 ) (right *node[N, T]) {
 	return tr.splitNodeLargestAxisEdgeSnap(r, left)
@@ -201,12 +249,31 @@ func (n *node[N, T]) orderToLeft(idx int) int {
 // go:noinline
 func (tr *RTreeGN[N, T]) copy(n *node[N, T]) *node[N, T] {
 	n2 := tr.newNode(n.leaf())
-	*n2 = *n
+	// The header is copied by field because the refs of a shared node may be
+	// changed by other trees.
+	n2.count = n.count
+	n2.rects = n.rects
+	if n.exps != nil {
+		exps := *n.exps
+		n2.exps = &exps
+	}
+	if n.vels != nil {
+		vels := *n.vels
+		n2.vels = &vels
+	}
 	if n2.leaf() {
 		copy(n2.items()[:n.count], n.items()[:n.count])
 	} else {
 		copy(n2.children()[:n.count], n.children()[:n.count])
+		if tr.arena != nil {
+			// the children are now also referenced by the copy
+			for _, child := range n2.children()[:n2.count] {
+				atomic.AddInt32(&child.refs, 1)
+			}
+		}
 	}
+	// this tree no longer uses the original
+	tr.unref(n)
 	return n2
 }
 
@@ -229,7 +296,7 @@ func (n *node[N, T]) rsearch(key N) int {
 }
 
 func (tr *RTreeGN[N, T]) nodeInsert(nr *rect[N], n *node[N, T], ir *rect[N],
-	data T,
+	data T, exp int64, vel velocity[N],
 ) (split, grown bool) {
 	if n.leaf() {
 		if n.count == maxEntries {
@@ -241,15 +308,57 @@ func (tr *RTreeGN[N, T]) nodeInsert(nr *rect[N], n *node[N, T], ir *rect[N],
 			index = n.rsearch(ir.min[0])
 			copy(n.rects[index+1:int(n.count)+1], n.rects[index:int(n.count)])
 			copy(items[index+1:int(n.count)+1], items[index:int(n.count)])
+			if n.exps != nil {
+				copy(n.exps[index+1:int(n.count)+1], n.exps[index:int(n.count)])
+			}
+			if n.vels != nil {
+				copy(n.vels[index+1:int(n.count)+1], n.vels[index:int(n.count)])
+			}
 		}
 		n.rects[index] = *ir
 		items[index] = data
+		n.setexp(index, exp)
+		n.setvel(index, vel)
 		n.count++
 		grown = !nr.contains(ir)
 		return false, grown
 	}
 
 	// choose a subtree
+	index := n.chooseSubtree(ir)
+	children := n.children()
+	tr.cow(&children[index])
+	split, grown = tr.nodeInsert(&n.rects[index], children[index], ir, data,
+		exp, vel)
+	if split {
+		if n.count == maxEntries {
+			return true, false
+		}
+		tr.splitChild(n, index)
+		return tr.nodeInsert(nr, n, ir, data, exp, vel)
+	}
+	if exp != 0 {
+		n.setexp(index, expmin(n.exp(index), exp))
+	}
+	if vel != (velocity[N]{}) || n.vels != nil {
+		v := n.vel(index)
+		v.expand(&vel)
+		n.setvel(index, v)
+	}
+	if grown {
+		// The child rectangle must expand to accomadate the new item.
+		n.rects[index].expand(ir)
+		if orderBranches {
+			n.orderToLeft(index)
+		}
+		grown = !nr.contains(ir)
+	}
+	return false, grown
+}
+
+// chooseSubtree returns the index of the child that the rect should be
+// inserted into.
+func (n *node[N, T]) chooseSubtree(ir *rect[N]) int {
 	rects := n.rects[:n.count]
 	index := -1
 	var narea N
@@ -266,47 +375,48 @@ func (tr *RTreeGN[N, T]) nodeInsert(nr *rect[N], n *node[N, T], ir *rect[N],
 	if index == -1 {
 		index = n.chooseLeastEnlargement(ir)
 	}
+	return index
+}
 
+// splitChild splits the child at index into two children.
+// The node must have room for one more child.
+func (tr *RTreeGN[N, T]) splitChild(n *node[N, T], index int) {
 	children := n.children()
-	tr.cow(&children[index])
-	split, grown = tr.nodeInsert(&n.rects[index], children[index], ir, data)
-	if split {
-		if n.count == maxEntries {
-			return true, false
+	left := children[index]
+	right := tr.splitNode(n.rects[index], left)
+	n.rects[index] = left.rect()
+	n.setexp(index, left.minexp())
+	n.setvel(index, left.velbounds())
+	if orderBranches {
+		copy(n.rects[index+2:int(n.count)+1],
+			n.rects[index+1:int(n.count)])
+		copy(children[index+2:int(n.count)+1],
+			children[index+1:int(n.count)])
+		if n.exps != nil {
+			copy(n.exps[index+2:int(n.count)+1],
+				n.exps[index+1:int(n.count)])
 		}
-		// split the child node
-		left := children[index]
-		right := tr.splitNode(n.rects[index], left)
-		n.rects[index] = left.rect()
-		if orderBranches {
-			copy(n.rects[index+2:int(n.count)+1],
-				n.rects[index+1:int(n.count)])
-			copy(children[index+2:int(n.count)+1],
-				children[index+1:int(n.count)])
-			n.rects[index+1] = right.rect()
-			children[index+1] = right
-			n.count++
-			if n.rects[index].min[0] > n.rects[index+1].min[0] {
-				n.swap(index+1, index)
-			}
-			index++
-			_ = n.orderToRight(index)
-		} else {
-			n.rects[n.count] = right.rect()
-			children[n.count] = right
-			n.count++
+		if n.vels != nil {
+			copy(n.vels[index+2:int(n.count)+1],
+				n.vels[index+1:int(n.count)])
 		}
-		return tr.nodeInsert(nr, n, ir, data)
-	}
-	if grown {
-		// The child rectangle must expand to accomadate the new item.
-		n.rects[index].expand(ir)
-		if orderBranches {
-			n.orderToLeft(index)
+		n.rects[index+1] = right.rect()
+		children[index+1] = right
+		n.setexp(index+1, right.minexp())
+		n.setvel(index+1, right.velbounds())
+		n.count++
+		if n.rects[index].min[0] > n.rects[index+1].min[0] {
+			n.swap(index+1, index)
 		}
-		grown = !nr.contains(ir)
+		index++
+		_ = n.orderToRight(index)
+	} else {
+		n.rects[n.count] = right.rect()
+		children[n.count] = right
+		n.setexp(int(n.count), right.minexp())
+		n.setvel(int(n.count), right.velbounds())
+		n.count++
 	}
-	return false, grown
 }
 
 func (r *rect[N]) area() N {
@@ -427,6 +537,16 @@ func (tr *RTreeGN[N, T]) moveRectAtIndexInto(from *node[N, T], index int,
 ) {
 	into.rects[into.count] = from.rects[index]
 	from.rects[index] = from.rects[from.count-1]
+	if from.exps != nil {
+		into.setexp(int(into.count), from.exps[index])
+		from.exps[index] = from.exps[from.count-1]
+		from.exps[from.count-1] = 0
+	}
+	if from.vels != nil {
+		into.setvel(int(into.count), from.vels[index])
+		from.vels[index] = from.vels[from.count-1]
+		from.vels[from.count-1] = velocity[N]{}
+	}
 	if from.leaf() {
 		into.items()[into.count] = from.items()[index]
 		from.items()[index] = from.items()[from.count-1]
@@ -510,10 +630,14 @@ func (n *node[N, T]) scan(iter func(min, max [2]N, data T) bool) bool {
 
 // Copy the tree.
 // This is a copy-on-write operation and is very fast because it only performs
-// a shadowed copy.
+// a shadowed copy. Watchers are not copied.
 func (tr *RTreeGN[N, T]) Copy() *RTreeGN[N, T] {
 	tr2 := new(RTreeGN[N, T])
 	*tr2 = *tr
+	tr2.watch = nil
+	if tr.arena != nil && tr.root != nil {
+		atomic.AddInt32(&tr.root.refs, 1)
+	}
 	tr.icow = atomic.AddUint64(&gcow, 1)
 	tr2.icow = atomic.AddUint64(&gcow, 1)
 	return tr2
@@ -522,6 +646,12 @@ func (tr *RTreeGN[N, T]) Copy() *RTreeGN[N, T] {
 // swap two rectanlges
 func (n *node[N, T]) swap(i, j int) {
 	n.rects[i], n.rects[j] = n.rects[j], n.rects[i]
+	if n.exps != nil {
+		n.exps[i], n.exps[j] = n.exps[j], n.exps[i]
+	}
+	if n.vels != nil {
+		n.vels[i], n.vels[j] = n.vels[j], n.vels[i]
+	}
 	if n.leaf() {
 		n.items()[i], n.items()[j] = n.items()[j], n.items()[i]
 	} else {
@@ -596,20 +726,32 @@ func (n *node[N, T]) qsort(s, e int, axis int, rev, max bool) {
 
 // Delete data from tree
 func (tr *RTreeGN[N, T]) Delete(min, max [2]N, data T) {
-	tr.delete(min, max, data)
+	if ok, old, _, _ := tr.delete(min, max, data); ok && tr.watch != nil {
+		tr.watch.notify(WatchEvent[N, T]{
+			Op: WatchDelete, OldMin: old.min, OldMax: old.max, OldData: data,
+		})
+	}
 }
 
-func (tr *RTreeGN[N, T]) delete(min, max [2]N, data T) bool {
+// delete an item from the tree, returning true, and the rect, expiration,
+// and velocity of the removed item, if the item was found.
+func (tr *RTreeGN[N, T]) delete(min, max [2]N, data T,
+) (bool, rect[N], int64, velocity[N]) {
 	ir := rect[N]{min, max}
 	if tr.root == nil || !tr.rect.contains(&ir) {
-		return false
+		return false, rect[N]{}, 0, velocity[N]{}
 	}
 	var reinsert []*node[N, T]
+	var old rect[N]
+	var exp int64
+	var vel velocity[N]
 	tr.cow(&tr.root)
-	removed, _ := tr.nodeDelete(&tr.rect, tr.root, &ir, data, &reinsert)
+	removed, _ := tr.nodeDelete(&tr.rect, tr.root, &ir, data, &reinsert, &old,
+		&exp, &vel)
 	if !removed {
-		return false
+		return false, rect[N]{}, 0, velocity[N]{}
 	}
+	tr.mods++
 	tr.count--
 	if len(reinsert) > 0 {
 		for _, n := range reinsert {
@@ -617,20 +759,24 @@ func (tr *RTreeGN[N, T]) delete(min, max [2]N, data T) bool {
 		}
 	}
 	if tr.count == 0 {
+		tr.free(tr.root)
 		tr.root = nil
 		tr.rect.min = [2]N{0, 0}
 		tr.rect.max = [2]N{0, 0}
 	} else {
 		for !tr.root.leaf() && tr.root.count == 1 {
+			old := tr.root
 			tr.root = tr.root.children()[0]
+			tr.free(old)
 		}
 	}
 	if len(reinsert) > 0 {
 		for i := range reinsert {
 			tr.nodeReinsert(reinsert[i])
+			tr.free(reinsert[i])
 		}
 	}
-	return true
+	return true, old, exp, vel
 }
 
 func compare[T any](a, b T) bool {
@@ -638,7 +784,7 @@ func compare[T any](a, b T) bool {
 }
 
 func (tr *RTreeGN[N, T]) nodeDelete(nr *rect[N], n *node[N, T], ir *rect[N], data T,
-	reinsert *[]*node[N, T],
+	reinsert *[]*node[N, T], old *rect[N], exp *int64, vel *velocity[N],
 ) (removed, shrunk bool) {
 	rects := n.rects[:n.count]
 	if n.leaf() {
@@ -646,14 +792,35 @@ func (tr *RTreeGN[N, T]) nodeDelete(nr *rect[N], n *node[N, T], ir *rect[N], dat
 		for i := 0; i < len(rects); i++ {
 			if ir.contains(&rects[i]) && compare(items[i], data) {
 				// found the target item to delete
+				*old = rects[i]
+				*exp = n.exp(i)
+				*vel = n.vel(i)
 				if orderLeaves {
 					copy(n.rects[i:n.count], n.rects[i+1:n.count])
 					copy(items[i:n.count], items[i+1:n.count])
+					if n.exps != nil {
+						copy(n.exps[i:n.count], n.exps[i+1:n.count])
+					}
+					if n.vels != nil {
+						copy(n.vels[i:n.count], n.vels[i+1:n.count])
+					}
 				} else {
 					n.rects[i] = n.rects[n.count-1]
 					items[i] = items[n.count-1]
+					if n.exps != nil {
+						n.exps[i] = n.exps[n.count-1]
+					}
+					if n.vels != nil {
+						n.vels[i] = n.vels[n.count-1]
+					}
 				}
 				items[len(rects)-1] = tr.empty
+				if n.exps != nil {
+					n.exps[len(rects)-1] = 0
+				}
+				if n.vels != nil {
+					n.vels[len(rects)-1] = velocity[N]{}
+				}
 				n.count--
 				shrunk = ir.onedge(nr)
 				if shrunk {
@@ -672,7 +839,7 @@ func (tr *RTreeGN[N, T]) nodeDelete(nr *rect[N], n *node[N, T], ir *rect[N], dat
 		crect := rects[i]
 		tr.cow(&children[i])
 		removed, shrunk = tr.nodeDelete(&rects[i], children[i], ir, data,
-			reinsert)
+			reinsert, old, exp, vel)
 		if !removed {
 			continue
 		}
@@ -681,11 +848,29 @@ func (tr *RTreeGN[N, T]) nodeDelete(nr *rect[N], n *node[N, T], ir *rect[N], dat
 			if orderBranches {
 				copy(n.rects[i:n.count], n.rects[i+1:n.count])
 				copy(children[i:n.count], children[i+1:n.count])
+				if n.exps != nil {
+					copy(n.exps[i:n.count], n.exps[i+1:n.count])
+				}
+				if n.vels != nil {
+					copy(n.vels[i:n.count], n.vels[i+1:n.count])
+				}
 			} else {
 				n.rects[i] = n.rects[n.count-1]
 				children[i] = children[n.count-1]
+				if n.exps != nil {
+					n.exps[i] = n.exps[n.count-1]
+				}
+				if n.vels != nil {
+					n.vels[i] = n.vels[n.count-1]
+				}
 			}
 			children[n.count-1] = nil
+			if n.exps != nil {
+				n.exps[n.count-1] = 0
+			}
+			if n.vels != nil {
+				n.vels[n.count-1] = velocity[N]{}
+			}
 			n.count--
 			*nr = n.rect()
 			return true, true
@@ -728,7 +913,8 @@ func (tr *RTreeGN[N, T]) nodeReinsert(n *node[N, T]) {
 		rects := n.rects[:n.count]
 		items := n.items()[:n.count]
 		for i := range rects {
-			tr.Insert(rects[i].min, rects[i].max, items[i])
+			tr.insert(rects[i].min, rects[i].max, items[i], n.exp(i),
+				n.vel(i))
 		}
 	} else {
 		children := n.children()[:n.count]
@@ -746,12 +932,20 @@ func (r *rect[N]) onedge(b *rect[N]) bool {
 
 // Replace an item.
 // If the old item does not exist then the new item is not inserted.
+// The new item keeps the expiration of the old item, if any.
 func (tr *RTreeGN[N, T]) Replace(
 	oldMin, oldMax [2]N, oldData T,
 	newMin, newMax [2]N, newData T,
 ) {
-	if tr.delete(oldMin, oldMax, oldData) {
-		tr.Insert(newMin, newMax, newData)
+	if ok, old, exp, _ := tr.delete(oldMin, oldMax, oldData); ok {
+		tr.insert(newMin, newMax, newData, exp, velocity[N]{})
+		if tr.watch != nil {
+			tr.watch.notify(WatchEvent[N, T]{
+				Op:     WatchReplace,
+				OldMin: old.min, OldMax: old.max, OldData: oldData,
+				NewMin: newMin, NewMax: newMax, NewData: newData,
+			})
+		}
 	}
 }
 
@@ -837,13 +1031,13 @@ func (tr *RTreeGN[N, T]) Nearby(
 	if tr.root == nil {
 		return
 	}
-	q := tr.qpool.Get().(*queue[N, T])
+	q := tr.qpool.Get().(*queue[N, N, T])
 	defer func() {
 		*q = (*q)[:0]
 		tr.qpool.Put(q)
 	}()
 
-	q.push(qnode[N, T]{
+	q.push(qnode[N, N, T]{
 		dist: 0,
 		rect: tr.rect,
 		node: tr.root,
@@ -862,7 +1056,7 @@ func (tr *RTreeGN[N, T]) Nearby(
 			if qn.node.leaf() {
 				items := qn.node.items()[:qn.node.count]
 				for i := 0; i < len(items); i++ {
-					q.push(qnode[N, T]{
+					q.push(qnode[N, N, T]{
 						dist: dist(rects[i].min, rects[i].max, items[i], true),
 						rect: rects[i],
 						data: items[i],
@@ -871,7 +1065,7 @@ func (tr *RTreeGN[N, T]) Nearby(
 			} else {
 				children := qn.node.children()[:qn.node.count]
 				for i := 0; i < len(children); i++ {
-					q.push(qnode[N, T]{
+					q.push(qnode[N, N, T]{
 						dist: dist(rects[i].min, rects[i].max, tr.empty, false),
 						rect: rects[i],
 						node: children[i],
@@ -882,16 +1076,19 @@ func (tr *RTreeGN[N, T]) Nearby(
 	}
 }
 
-type qnode[N numeric, T any] struct {
-	dist N           // distance to
+// qnode is an entry of the queue, which is ordered by the distance of type D.
+type qnode[D, N numeric, T any] struct {
+	dist D           // distance to
 	rect rect[N]     // item or node rect
 	data T           // item data (or empty for node)
 	node *node[N, T] // node (or nil for leaf data)
 }
 
-type queue[N numeric, T any] []qnode[N, T]
+// queue is a min-heap of nodes and items that is shared by the kNN-type
+// operations, such as Nearby and Raycast.
+type queue[D, N numeric, T any] []qnode[D, N, T]
 
-func (q *queue[N, T]) push(node qnode[N, T]) {
+func (q *queue[D, N, T]) push(node qnode[D, N, T]) {
 	*q = append(*q, node)
 	nodes := *q
 	i := len(nodes) - 1
@@ -902,12 +1099,12 @@ func (q *queue[N, T]) push(node qnode[N, T]) {
 	}
 }
 
-func (q *queue[N, T]) pop() (qnode[N, T], bool) {
+func (q *queue[D, N, T]) pop() (qnode[D, N, T], bool) {
 	nodes := *q
 	if len(nodes) == 0 {
-		return qnode[N, T]{}, false
+		return qnode[D, N, T]{}, false
 	}
-	var n qnode[N, T]
+	var n qnode[D, N, T]
 	n, nodes[0] = nodes[0], nodes[len(*q)-1]
 	nodes = nodes[:len(nodes)-1]
 	*q = nodes
@@ -960,6 +1157,10 @@ func (r *rect[N]) boxDist(b *rect[N]) N {
 
 // Clear will delete all items.
 func (tr *RTreeGN[N, T]) Clear() {
+	if tr.root != nil {
+		tr.unref(tr.root)
+	}
+	tr.mods++
 	tr.count = 0
 	tr.rect = rect[N]{}
 	tr.root = nil
@@ -610,6 +610,9 @@ func TestSane(t *testing.T) {
 }
 
 func rSane[T comparable](tr *RTreeG[T]) error {
+	if err := tr.Validate(); err != nil {
+		return err
+	}
 	if tr.base.root == nil {
 		if tr.base.count != 0 {
 			return errors.New("nil root, count not zero")
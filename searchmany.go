@@ -0,0 +1,100 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+// SearchMany searches for items that intersect any of the provided windows,
+// where each window is a {min, max} pair. The tree is traversed once, and each
+// node is only tested against the windows that intersected its parent.
+// The iter function is called for every window that an item intersects,
+// along with the index of the window. Returning false stops the search for
+// that window only.
+func (tr *RTreeGN[N, T]) SearchMany(windows [][2][2]N,
+	iter func(windowIdx int, min, max [2]N, data T) bool,
+) {
+	if tr.root == nil {
+		return
+	}
+	var active []int
+	for i := range windows {
+		target := rect[N]{windows[i][0], windows[i][1]}
+		if target.intersects(&tr.rect) {
+			active = append(active, i)
+		}
+	}
+	if len(active) == 0 {
+		return
+	}
+	s := searchMany[N, T]{
+		windows: windows,
+		done:    make([]bool, len(windows)),
+		remain:  len(active),
+		iter:    iter,
+	}
+	s.search(tr.root, active)
+}
+
+type searchMany[N numeric, T any] struct {
+	windows [][2][2]N
+	done    []bool // windows that have been stopped
+	remain  int    // number of windows not yet stopped
+	buf     []int  // stack of active windows for each level
+	iter    func(windowIdx int, min, max [2]N, data T) bool
+}
+
+func (s *searchMany[N, T]) intersects(w int, r *rect[N]) bool {
+	target := rect[N]{s.windows[w][0], s.windows[w][1]}
+	return !s.done[w] && r.intersects(&target)
+}
+
+// search the node for the active windows. Returns false when all windows
+// have been stopped.
+func (s *searchMany[N, T]) search(n *node[N, T], active []int) bool {
+	rects := n.rects[:n.count]
+	if n.leaf() {
+		items := n.items()
+		for i := 0; i < len(rects); i++ {
+			for _, w := range active {
+				if !s.intersects(w, &rects[i]) {
+					continue
+				}
+				if !s.iter(w, rects[i].min, rects[i].max, items[i]) {
+					s.done[w] = true
+					s.remain--
+					if s.remain == 0 {
+						return false
+					}
+				}
+			}
+		}
+		return true
+	}
+	children := n.children()
+	for i := 0; i < len(rects); i++ {
+		mark := len(s.buf)
+		for _, w := range active {
+			if s.intersects(w, &rects[i]) {
+				s.buf = append(s.buf, w)
+			}
+		}
+		if len(s.buf) > mark {
+			if !s.search(children[i], s.buf[mark:]) {
+				return false
+			}
+		}
+		s.buf = s.buf[:mark]
+	}
+	return true
+}
+
+// SearchMany searches for items that intersect any of the provided windows,
+// where each window is a {min, max} pair. The tree is traversed once.
+// The iter function is called for every window that an item intersects,
+// along with the index of the window. Returning false stops the search for
+// that window only.
+func (tr *RTreeG[T]) SearchMany(windows [][2][2]float64,
+	iter func(windowIdx int, min, max [2]float64, data T) bool,
+) {
+	tr.base.SearchMany(windows, iter)
+}
@@ -0,0 +1,48 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSearchMany(t *testing.T) {
+	rand.Seed(seed)
+	var tr RTreeG[int]
+	for i := 0; i < 50_000; i++ {
+		r := randRect('m')
+		tr.Insert(r.min, r.max, i)
+	}
+	// a 16x16 grid of tiles, plus one window outside of the tree
+	var windows [][2][2]float64
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			windows = append(windows, [2][2]float64{
+				{-180 + float64(x)*22.5, -90 + float64(y)*11.25},
+				{-180 + float64(x+1)*22.5, -90 + float64(y+1)*11.25},
+			})
+		}
+	}
+	windows = append(windows, [2][2]float64{{500, 500}, {600, 600}})
+	counts := make([]int, len(windows))
+	tr.SearchMany(windows, func(w int, min, max [2]float64, i int) bool {
+		counts[w]++
+		// stop the first window early
+		return w != 0 || counts[w] < 3
+	})
+	for w := range windows {
+		var expect int
+		tr.Search(windows[w][0], windows[w][1],
+			func(min, max [2]float64, i int) bool {
+				expect++
+				return w != 0 || expect < 3
+			},
+		)
+		if counts[w] != expect {
+			t.Fatalf("window %d: expected %d, got %d", w, expect, counts[w])
+		}
+	}
+}
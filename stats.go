@@ -0,0 +1,104 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import "unsafe"
+
+// Stats are statistics about the structure of a tree.
+type Stats struct {
+	// Height is the number of levels, including the leaves.
+	Height int
+	// Levels is the number of nodes at each level, starting at the root.
+	Levels []int
+	// Leaves and Branches are the number of leaf and branch nodes.
+	Leaves   int
+	Branches int
+	// LeafFill and BranchFill are histograms of node fill, where LeafFill[i]
+	// is the number of leaves that have i entries.
+	LeafFill   []int
+	BranchFill []int
+	// Overlap is the total area where sibling nodes overlap, and AvgOverlap
+	// is the average of that area for each branch.
+	Overlap    float64
+	AvgOverlap float64
+	// Area is the total area of all nodes, not including the root.
+	Area float64
+	// Dead is the number of unused item entries in all leaves.
+	Dead int
+	// Bytes is the approximate memory used by nodes that are owned by this
+	// tree, and SharedNodes and SharedBytes are the same for the nodes that
+	// are shared with other trees by Copy.
+	Bytes       int
+	SharedNodes int
+	SharedBytes int
+}
+
+// Stats returns statistics about the structure of the tree.
+// The itemSize is the number of bytes used by each item outside of the tree,
+// such as the data that an item pointer references, and is added to the
+// bytes of each leaf.
+func (tr *RTreeGN[N, T]) Stats(itemSize int) Stats {
+	s := Stats{
+		LeafFill:   make([]int, maxEntries+1),
+		BranchFill: make([]int, maxEntries+1),
+	}
+	if tr.root != nil {
+		tr.stats(&s, tr.root, 0, itemSize)
+	}
+	if s.Branches > 0 {
+		s.AvgOverlap = s.Overlap / float64(s.Branches)
+	}
+	return s
+}
+
+func (tr *RTreeGN[N, T]) stats(s *Stats, n *node[N, T], depth int,
+	itemSize int,
+) {
+	if depth == len(s.Levels) {
+		s.Levels = append(s.Levels, 0)
+		s.Height = len(s.Levels)
+	}
+	s.Levels[depth]++
+	var bytes int
+	if n.leaf() {
+		s.Leaves++
+		s.LeafFill[n.count]++
+		s.Dead += maxEntries - int(n.count)
+		bytes = int(unsafe.Sizeof(leafNode[N, T]{})) + int(n.count)*itemSize
+	} else {
+		s.Branches++
+		s.BranchFill[n.count]++
+		bytes = int(unsafe.Sizeof(branchNode[N, T]{}))
+	}
+	if n.exps != nil {
+		bytes += int(unsafe.Sizeof(*n.exps))
+	}
+	if n.vels != nil {
+		bytes += int(unsafe.Sizeof(*n.vels))
+	}
+	if n.icow != tr.icow {
+		s.SharedNodes++
+		s.SharedBytes += bytes
+	} else {
+		s.Bytes += bytes
+	}
+	if n.leaf() {
+		return
+	}
+	rects := n.rects[:n.count]
+	for i := range rects {
+		s.Area += float64(rects[i].area())
+		for j := i + 1; j < len(rects); j++ {
+			s.Overlap += rects[i].overlapArea(&rects[j])
+		}
+		tr.stats(s, n.children()[i], depth+1, itemSize)
+	}
+}
+
+// Stats returns statistics about the structure of the tree.
+// The itemSize is the number of bytes used by each item outside of the tree.
+func (tr *RTreeG[T]) Stats(itemSize int) Stats {
+	return tr.base.Stats(itemSize)
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	rand.Seed(seed)
+	var tr RTreeG[testPoint]
+	s := tr.Stats(0)
+	if s.Height != 0 || s.Leaves != 0 || s.Bytes != 0 {
+		t.Fatalf("expected empty stats, got %+v", s)
+	}
+	N := 100_000
+	mins := make([][2]float64, N)
+	pts := make([]testPoint, N)
+	for i := range pts {
+		pts[i] = randTestPoint()
+		mins[i] = pts[i].coord()
+	}
+	tr.Load(mins, mins, pts)
+	s = tr.Stats(0)
+	if s.Height != 3 || len(s.Levels) != 3 || s.Levels[0] != 1 {
+		t.Fatalf("expected height 3, got %d %v", s.Height, s.Levels)
+	}
+	if s.Levels[2] != s.Leaves || s.Levels[0]+s.Levels[1] != s.Branches {
+		t.Fatalf("levels %v do not match %d/%d", s.Levels, s.Leaves, s.Branches)
+	}
+	var items, leaves, branches int
+	for i := range s.LeafFill {
+		items += s.LeafFill[i] * i
+		leaves += s.LeafFill[i]
+		branches += s.BranchFill[i]
+	}
+	if items != N || leaves != s.Leaves || branches != s.Branches {
+		t.Fatalf("bad histograms")
+	}
+	if s.Dead != s.Leaves*maxEntries-N {
+		t.Fatalf("expected %d dead, got %d", s.Leaves*maxEntries-N, s.Dead)
+	}
+	if s.Area <= 0 || s.Overlap < 0 || s.Overlap > s.Area ||
+		s.AvgOverlap != s.Overlap/float64(s.Branches) {
+		t.Fatalf("bad areas %f %f %f", s.Area, s.Overlap, s.AvgOverlap)
+	}
+	if s.SharedNodes != 0 || s.SharedBytes != 0 || s.Bytes <= 0 {
+		t.Fatalf("expected no shared nodes, got %d", s.SharedNodes)
+	}
+	if s2 := tr.Stats(100); s2.Bytes != s.Bytes+100*N {
+		t.Fatalf("expected %d bytes, got %d", s.Bytes+100*N, s2.Bytes)
+	}
+
+	// everything is shared after a copy, until it's written to
+	tr2 := tr.Copy()
+	s = tr2.Stats(0)
+	if s.SharedNodes != s.Leaves+s.Branches || s.Bytes != 0 {
+		t.Fatalf("expected all shared nodes, got %d", s.SharedNodes)
+	}
+	pt := randTestPoint()
+	tr2.Insert(pt.coord(), pt.coord(), pt)
+	s = tr2.Stats(0)
+	// the path to the leaf is copied, plus a new node for each node on the
+	// path that was split, because the loaded nodes are full
+	owned := s.Leaves + s.Branches - s.SharedNodes
+	if owned < s.Height || owned > s.Height*2 || s.Bytes == 0 {
+		t.Fatalf("expected one owned path, got %d owned", owned)
+	}
+}
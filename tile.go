@@ -0,0 +1,145 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import "math"
+
+// Tiles are the standard web map z/x/y tiles in Web Mercator, where tile 0/0/0
+// covers the world, and y increases to the south. The tree rects are
+// longitude and latitude degrees, in that order.
+
+// tileX returns the tile x coordinate, with a fraction, of the longitude.
+func tileX(lon float64, z int) float64 {
+	return (lon + 180) / 360 * float64(int(1)<<z)
+}
+
+// tileY returns the tile y coordinate, with a fraction, of the latitude.
+func tileY(lat float64, z int) float64 {
+	sin := math.Sin(lat * rad)
+	y := 0.5 - math.Log((1+sin)/(1-sin))/(4*math.Pi)
+	return y * float64(int(1)<<z)
+}
+
+func tileLon(x float64, z int) float64 {
+	return x/float64(int(1)<<z)*360 - 180
+}
+
+func tileLat(y float64, z int) float64 {
+	n := math.Pi * (1 - 2*y/float64(int(1)<<z))
+	return math.Atan(math.Sinh(n)) / rad
+}
+
+// TileBounds returns the longitude and latitude bounds of the tile.
+func TileBounds(z, x, y int) (min, max [2]float64) {
+	return tileBounds(z, x, y, 0, false)
+}
+
+// tileBounds returns the tile bounds, expanded by the buffer, which is a
+// fraction of the tile size. The buffer may extend the longitudes past the
+// antimeridian. When poles is true, the top and bottom rows of tiles are
+// extended to the poles, which Web Mercator can't reach.
+func tileBounds(z, x, y int, buffer float64, poles bool,
+) (min, max [2]float64) {
+	n := float64(int(1) << z)
+	x0, x1 := float64(x)-buffer, float64(x+1)+buffer
+	y0, y1 := fmax(float64(y)-buffer, 0), fmin(float64(y+1)+buffer, n)
+	min = [2]float64{tileLon(x0, z), tileLat(y1, z)}
+	max = [2]float64{tileLon(x1, z), tileLat(y0, z)}
+	if poles && y1 == n {
+		min[1] = -90
+	}
+	if poles && y0 == 0 {
+		max[1] = 90
+	}
+	return min, max
+}
+
+// Tiles calls iter for each tile at zoom z that intersects the longitude and
+// latitude rect, such as the Bounds of the tree or the rect of an item.
+// Rects that cross the antimeridian, in either the form that is passed to
+// InsertGeo or the form that it is stored in, include the tiles on both
+// sides.
+func Tiles(min, max [2]float64, z int, iter func(x, y int) bool) {
+	min, max = geoWindow(min, max)
+	n := int(1) << z
+	clamp := func(v float64) int {
+		if v < 0 {
+			return 0
+		}
+		if v >= float64(n) {
+			return n - 1
+		}
+		return int(v)
+	}
+	x0, x1 := clamp(tileX(min[0], z)), clamp(tileX(max[0], z))
+	y0, y1 := clamp(tileY(max[1], z)), clamp(tileY(min[1], z))
+	// The columns are split in two when the rect crosses the antimeridian,
+	// unless the two parts meet.
+	cols := [2][2]int{{x0, x1}}
+	ncols := 1
+	if min[0] > max[0] {
+		if x1+1 >= x0 {
+			cols[0] = [2]int{0, n - 1}
+		} else {
+			cols[0], cols[1] = [2]int{0, x1}, [2]int{x0, n - 1}
+			ncols = 2
+		}
+	}
+	for y := y0; y <= y1; y++ {
+		for _, col := range cols[:ncols] {
+			for x := col[0]; x <= col[1]; x++ {
+				if !iter(x, y) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// SearchTile searches for items that intersect the z/x/y tile. The buffer
+// expands the tile on all sides, and is a fraction of the tile size, such as
+// 0.0625 for 256 pixels of a 4096 extent.
+//
+// The tile is searched with SearchGeo, so items that were inserted with
+// InsertGeo and cross the antimeridian are found in the tiles on both sides,
+// with their rects in the form that they were inserted with.
+func (tr *RTreeG[T]) SearchTile(z, x, y int, buffer float64,
+	iter func(min, max [2]float64, data T) bool,
+) {
+	min, max := tileBounds(z, x, y, buffer, true)
+	tr.SearchGeo(min, max, iter)
+}
+
+// SearchTileLocal is like SearchTile, but the item rects are converted to the
+// integer coordinates of the tile, where 0,0 is the top-left corner and
+// extent,extent is the bottom-right corner. The rects are clipped to the
+// tile, expanded by the buffer.
+func (tr *RTreeG[T]) SearchTileLocal(z, x, y int, buffer float64, extent int,
+	iter func(min, max [2]int, data T) bool,
+) {
+	e := float64(extent)
+	lo, hi := -buffer*e, e+buffer*e
+	local := func(v float64, tile int) int {
+		v = (v - float64(tile)) * e
+		return int(math.Round(fmin(fmax(v, lo), hi)))
+	}
+	wmin, wmax := tileBounds(z, x, y, buffer, true)
+	tr.SearchTile(z, x, y, buffer, func(min, max [2]float64, data T) bool {
+		// Use the longitudes of the item that are on the side of the
+		// antimeridian that the tile is on.
+		if min[0] > max[0] {
+			max[0] += 360
+		}
+		for _, shift := range [...]float64{0, -360, 360} {
+			if min[0]+shift <= wmax[0] && max[0]+shift >= wmin[0] {
+				min[0], max[0] = min[0]+shift, max[0]+shift
+				break
+			}
+		}
+		lmin := [2]int{local(tileX(min[0], z), x), local(tileY(max[1], z), y)}
+		lmax := [2]int{local(tileX(max[0], z), x), local(tileY(min[1], z), y)}
+		return iter(lmin, lmax, data)
+	})
+}
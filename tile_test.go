@@ -0,0 +1,154 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestTiles(t *testing.T) {
+	rand.Seed(seed)
+	min, max := TileBounds(0, 0, 0)
+	if min[0] != -180 || max[0] != 180 || math.Abs(max[1]-85.0511287798) > 1e-9 ||
+		math.Abs(min[1]+85.0511287798) > 1e-9 {
+		t.Fatalf("unexpected bounds %v %v", min, max)
+	}
+	min, max = TileBounds(1, 1, 0)
+	if min[0] != 0 || max[0] != 180 || math.Abs(min[1]) > 1e-9 {
+		t.Fatalf("unexpected bounds %v %v", min, max)
+	}
+
+	// tiles cover the rect
+	var tiles [][2]int
+	Tiles([2]float64{-10, -10}, [2]float64{10, 10}, 2,
+		func(x, y int) bool {
+			tiles = append(tiles, [2]int{x, y})
+			return true
+		},
+	)
+	if len(tiles) != 4 || tiles[0] != [2]int{1, 1} || tiles[3] != [2]int{2, 2} {
+		t.Fatalf("unexpected tiles %v", tiles)
+	}
+
+	var tr RTreeG[testPoint]
+	pts := make([]testPoint, 10_000)
+	for i := range pts {
+		pts[i] = randTestPoint()
+		tr.Insert(pts[i].coord(), pts[i].coord(), pts[i])
+	}
+	// every item is found in exactly one tile
+	z := 3
+	var total int
+	bmin, bmax := tr.Bounds()
+	Tiles(bmin, bmax, z, func(x, y int) bool {
+		tr.SearchTile(z, x, y, 0, func(min, _ [2]float64, _ testPoint) bool {
+			tx, ty := tileX(min[0], z), tileY(min[1], z)
+			if int(tx) == x && (int(ty) == y ||
+				(y == 0 && ty < 0) || (y == 1<<z-1 && ty >= float64(int(1)<<z))) {
+				total++
+			}
+			return true
+		})
+		return true
+	})
+	if total != len(pts) {
+		t.Fatalf("expected %d items, got %d", len(pts), total)
+	}
+
+	// a buffer finds more items, and local coords are clipped to it
+	var count, bcount int
+	tr.SearchTile(z, 4, 3, 0, func(_, _ [2]float64, _ testPoint) bool {
+		count++
+		return true
+	})
+	tr.SearchTileLocal(z, 4, 3, 0.25, 4096,
+		func(min, max [2]int, pt testPoint) bool {
+			if min != max || min[0] < -1024 || min[0] > 5120 ||
+				min[1] < -1024 || min[1] > 5120 {
+				t.Fatalf("unexpected local coords %v %v", min, max)
+			}
+			lx := (tileX(pt.x, z) - 4) * 4096
+			ly := (tileY(pt.y, z) - 3) * 4096
+			if math.Abs(lx-float64(min[0])) > 0.5 ||
+				math.Abs(ly-float64(min[1])) > 0.5 {
+				t.Fatalf("unexpected local coords %v for %f %f", min, lx, ly)
+			}
+			bcount++
+			return true
+		},
+	)
+	if count == 0 || bcount <= count {
+		t.Fatalf("expected more items with a buffer, got %d %d", count, bcount)
+	}
+}
+
+func TestTilesAntimeridian(t *testing.T) {
+	collect := func(min, max [2]float64, z int) [][2]int {
+		var tiles [][2]int
+		Tiles(min, max, z, func(x, y int) bool {
+			tiles = append(tiles, [2]int{x, y})
+			return true
+		})
+		return tiles
+	}
+	// both the InsertGeo form and the stored form of a crossing rect
+	exp := "[[0 1] [3 1]]"
+	for _, max := range [][2]float64{{-170, 10}, {190, 10}} {
+		tiles := collect([2]float64{170, 1}, max, 2)
+		if fmt.Sprint(tiles) != exp {
+			t.Fatalf("expected %s, got %v", exp, tiles)
+		}
+	}
+	// the two sides meet
+	if tiles := collect([2]float64{-10, 1}, [2]float64{-20, 10}, 2); len(tiles) != 4 {
+		t.Fatalf("expected 4 tiles, got %v", tiles)
+	}
+
+	var tr RTreeG[int]
+	tr.InsertGeo([2]float64{170, 1}, [2]float64{-170, 10}, 1)
+	tr.Insert([2]float64{-150, 5}, [2]float64{-150, 5}, 2)
+	bmin, bmax := tr.Bounds()
+	tiles := collect(bmin, bmax, 2)
+	exp = "[[0 1] [1 1] [2 1] [3 1]]"
+	if fmt.Sprint(tiles) != exp {
+		t.Fatalf("expected %s, got %v", exp, tiles)
+	}
+	search := func(x int, buffer float64) []int {
+		var found []int
+		tr.SearchTile(2, x, 1, buffer, func(min, max [2]float64, data int) bool {
+			if data == 1 && (min[0] != 170 || max[0] != -170) {
+				t.Fatalf("unexpected rect %v %v", min, max)
+			}
+			found = append(found, data)
+			return true
+		})
+		sort.Ints(found)
+		return found
+	}
+	// the crossing item is found on both sides, in its InsertGeo form
+	if found := search(0, 0); fmt.Sprint(found) != "[1 2]" {
+		t.Fatalf("expected [1 2], got %v", found)
+	}
+	if found := search(3, 0); fmt.Sprint(found) != "[1]" {
+		t.Fatalf("expected [1], got %v", found)
+	}
+	// a buffer reaches across the antimeridian
+	if found := search(3, 0.5); fmt.Sprint(found) != "[1 2]" {
+		t.Fatalf("expected [1 2], got %v", found)
+	}
+	// local coords use the side of the item that the tile is on
+	for x, exp := range map[int][2]int{0: {0, 455}, 3: {3641, 4096}} {
+		tr.SearchTileLocal(2, x, 1, 0, 4096, func(min, max [2]int, data int) bool {
+			if data == 1 && (min[0] != exp[0] || max[0] != exp[1]) {
+				t.Fatalf("tile %d: unexpected local coords %v %v", x, min, max)
+			}
+			return true
+		})
+	}
+}
@@ -0,0 +1,432 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+// TPRTreeGN is a time-parameterized R-tree for moving objects.
+// It's an RTreeGN where each object also has a velocity. Objects are stored
+// with the rect they have at the reference time of the tree, and each branch
+// entry keeps the minimum and maximum velocities of its subtree, so a node
+// rect can be moved to any time without touching the objects inside. This
+// allows for querying where objects will be at a time without updating the
+// tree on every tick.
+//
+// The reference time is the time of the first insert. Node rects grow with
+// the spread of the velocities below them as the query time moves away from
+// the reference time, so a long running tree should use SetReference every
+// so often to rebuild the tree at the current time.
+type TPRTreeGN[N numeric, T any] struct {
+	base RTreeGN[N, T]
+	ref  N // reference time
+}
+
+// Velocities are stored per node in the optional `node[N,T].vels` array,
+// which is parallel to `node[N,T].rects`. For a leaf, each entry is the
+// velocity of the item, where min and max are the same. For a branch, each
+// entry is the minimum and maximum velocities in the child subtree. The array
+// is only allocated for nodes that have seen a moving item.
+type velocity[N numeric] struct {
+	min [2]N
+	max [2]N
+}
+
+func (v *velocity[N]) expand(b *velocity[N]) {
+	for i := 0; i < 2; i++ {
+		v.min[i] = fmin(v.min[i], b.min[i])
+		v.max[i] = fmax(v.max[i], b.max[i])
+	}
+}
+
+func (v *velocity[N]) contains(b *velocity[N]) bool {
+	return !(b.min[0] < v.min[0] || b.max[0] > v.max[0] ||
+		b.min[1] < v.min[1] || b.max[1] > v.max[1])
+}
+
+func (n *node[N, T]) vel(i int) velocity[N] {
+	if n.vels == nil {
+		return velocity[N]{}
+	}
+	return n.vels[i]
+}
+
+func (n *node[N, T]) setvel(i int, vel velocity[N]) {
+	if n.vels == nil {
+		if vel == (velocity[N]{}) {
+			return
+		}
+		n.vels = new([maxEntries]velocity[N])
+	}
+	n.vels[i] = vel
+}
+
+// velbounds returns the minimum and maximum velocities of all entries in the
+// node.
+func (n *node[N, T]) velbounds() velocity[N] {
+	if n.vels == nil {
+		return velocity[N]{}
+	}
+	vel := n.vels[0]
+	for i := 1; i < int(n.count); i++ {
+		vel.expand(&n.vels[i])
+	}
+	return vel
+}
+
+// move returns the rect moved by the velocity for dt.
+// When dt is negative the rect expands in reverse, which keeps node rects
+// conservative in both directions.
+func (r *rect[N]) move(vel *velocity[N], dt N) rect[N] {
+	if dt == 0 {
+		return *r
+	}
+	lo, hi := vel.min, vel.max
+	if dt < 0 {
+		lo, hi = hi, lo
+	}
+	return rect[N]{
+		min: [2]N{r.min[0] + lo[0]*dt, r.min[1] + lo[1]*dt},
+		max: [2]N{r.max[0] + hi[0]*dt, r.max[1] + hi[1]*dt},
+	}
+}
+
+// Len returns the number of items in tree
+func (tr *TPRTreeGN[N, T]) Len() int {
+	return tr.base.Len()
+}
+
+// Reference returns the reference time of the tree.
+func (tr *TPRTreeGN[N, T]) Reference() N {
+	return tr.ref
+}
+
+// SetReference moves the reference time of the tree to t, and rebuilds the
+// tree so that its node rects are tight at that time.
+func (tr *TPRTreeGN[N, T]) SetReference(t N) {
+	var rects []rect[N]
+	var vels []velocity[N]
+	var items []T
+	if tr.base.root != nil {
+		tr.base.root.scanAt(0, func(r *rect[N], vel *velocity[N], data T) bool {
+			rects = append(rects, *r)
+			vels = append(vels, *vel)
+			items = append(items, data)
+			return true
+		})
+	}
+	dt := t - tr.ref
+	tr.base.Clear()
+	tr.ref = t
+	for i := range rects {
+		r := rects[i].move(&vels[i], dt)
+		tr.base.insert(r.min, r.max, items[i], 0, vels[i])
+	}
+}
+
+// Insert an object with the rect it has at time t, moving at velocity vel.
+func (tr *TPRTreeGN[N, T]) Insert(min, max, vel [2]N, t N, data T) {
+	if tr.base.root == nil {
+		tr.ref = t
+	}
+	v := velocity[N]{vel, vel}
+	r := rect[N]{min, max}
+	r = r.move(&v, tr.ref-t)
+	tr.base.insert(r.min, r.max, data, 0, v)
+}
+
+// Delete an object. The min, max, vel, and t must be the same as the values
+// that the object was inserted with.
+func (tr *TPRTreeGN[N, T]) Delete(min, max, vel [2]N, t N, data T) {
+	tr.delete(min, max, vel, t, data)
+}
+
+func (tr *TPRTreeGN[N, T]) delete(min, max, vel [2]N, t N, data T) bool {
+	v := velocity[N]{vel, vel}
+	r := rect[N]{min, max}
+	r = r.move(&v, tr.ref-t)
+	if ok, _, _, _ := tr.base.delete(r.min, r.max, data); ok {
+		return true
+	}
+	if tr.base.root == nil {
+		return false
+	}
+	// Moving the tree with SetReference may round the stored rect a little
+	// differently, so look for the object near the rect.
+	target := r
+	for i := 0; i < 2; i++ {
+		target.min[i] -= N(tprPad * (1 + fabs(float64(target.min[i]))))
+		target.max[i] += N(tprPad * (1 + fabs(float64(target.max[i]))))
+	}
+	var found bool
+	tr.base.root.searchAt(target, 0,
+		func(r2 *rect[N], vel *velocity[N], data2 T) bool {
+			if *vel == v && compare(data2, data) {
+				r, found = *r2, true
+				return false
+			}
+			return true
+		},
+	)
+	if !found {
+		return false
+	}
+	ok, _, _, _ := tr.base.delete(r.min, r.max, data)
+	return ok
+}
+
+// tprPad is a variable so that the conversion to integer types truncates to
+// zero.
+var tprPad = 1e-9
+
+func fabs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Replace an object.
+// If the old object does not exist then the new object is not inserted.
+func (tr *TPRTreeGN[N, T]) Replace(
+	oldMin, oldMax, oldVel [2]N, oldT N, oldData T,
+	newMin, newMax, newVel [2]N, newT N, newData T,
+) {
+	if tr.delete(oldMin, oldMax, oldVel, oldT, oldData) {
+		tr.Insert(newMin, newMax, newVel, newT, newData)
+	}
+}
+
+// Bounds returns the minimum bounding rect of all objects at time t.
+func (tr *TPRTreeGN[N, T]) Bounds(t N) (min, max [2]N) {
+	if tr.base.root == nil {
+		return
+	}
+	vel := tr.base.root.velbounds()
+	r := tr.base.rect.move(&vel, t-tr.ref)
+	return r.min, r.max
+}
+
+// Scan all objects in the tree.
+// The iter function receives the rect of each object at the reference time of
+// the tree, which together with its velocity and Reference can be used to
+// Delete the object.
+func (tr *TPRTreeGN[N, T]) Scan(iter func(min, max, vel [2]N, data T) bool) {
+	if tr.base.root != nil {
+		tr.base.root.scanAt(0,
+			func(r *rect[N], vel *velocity[N], data T) bool {
+				return iter(r.min, r.max, vel.min, data)
+			},
+		)
+	}
+}
+
+func (n *node[N, T]) scanAt(dt N,
+	iter func(r *rect[N], vel *velocity[N], data T) bool,
+) bool {
+	for i := 0; i < int(n.count); i++ {
+		if !n.leaf() {
+			if !n.children()[i].scanAt(dt, iter) {
+				return false
+			}
+			continue
+		}
+		vel := n.vel(i)
+		r := n.rects[i].move(&vel, dt)
+		if !iter(&r, &vel, n.items()[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// SearchAt searches for objects that will intersect the provided rectangle at
+// time t. The iter function receives the rect of each object at time t.
+func (tr *TPRTreeGN[N, T]) SearchAt(min, max [2]N, t N,
+	iter func(min, max [2]N, data T) bool,
+) {
+	if tr.base.root != nil {
+		tr.base.root.searchAt(rect[N]{min, max}, t-tr.ref,
+			func(r *rect[N], vel *velocity[N], data T) bool {
+				return iter(r.min, r.max, data)
+			},
+		)
+	}
+}
+
+func (n *node[N, T]) searchAt(target rect[N], dt N,
+	iter func(r *rect[N], vel *velocity[N], data T) bool,
+) bool {
+	for i := 0; i < int(n.count); i++ {
+		vel := n.vel(i)
+		r := n.rects[i].move(&vel, dt)
+		if !r.intersects(&target) {
+			continue
+		}
+		if n.leaf() {
+			if !iter(&r, &vel, n.items()[i]) {
+				return false
+			}
+		} else if !n.children()[i].searchAt(target, dt, iter) {
+			return false
+		}
+	}
+	return true
+}
+
+// NearbyAt returns the objects nearest to a point at time t, ordered from the
+// smallest box distance to the largest. The iter function receives the rect
+// of each object at time t.
+func (tr *TPRTreeGN[N, T]) NearbyAt(point [2]N, t N,
+	iter func(min, max [2]N, data T, dist N) bool,
+) {
+	if tr.base.root == nil {
+		return
+	}
+	q := tr.base.qpool.Get().(*queue[N, N, T])
+	defer func() {
+		*q = (*q)[:0]
+		tr.base.qpool.Put(q)
+	}()
+	target := rect[N]{point, point}
+	dt := t - tr.ref
+	q.push(qnode[N, N, T]{node: tr.base.root})
+	for {
+		qn, ok := q.pop()
+		if !ok {
+			return
+		}
+		if qn.node == nil {
+			if !iter(qn.rect.min, qn.rect.max, qn.data, qn.dist) {
+				return
+			}
+			continue
+		}
+		n := qn.node
+		for i := 0; i < int(n.count); i++ {
+			vel := n.vel(i)
+			r := n.rects[i].move(&vel, dt)
+			qn := qnode[N, N, T]{dist: target.boxDist(&r), rect: r}
+			if n.leaf() {
+				qn.data = n.items()[i]
+			} else {
+				qn.node = n.children()[i]
+			}
+			q.push(qn)
+		}
+	}
+}
+
+// Copy the tree.
+// This is a copy-on-write operation and is very fast because it only performs
+// a shadowed copy.
+func (tr *TPRTreeGN[N, T]) Copy() *TPRTreeGN[N, T] {
+	return &TPRTreeGN[N, T]{*tr.base.Copy(), tr.ref}
+}
+
+// Clear will delete all items.
+func (tr *TPRTreeGN[N, T]) Clear() {
+	tr.base.Clear()
+}
+
+// Validate checks the integrity of the tree and returns an error describing
+// the first problem that was found, or nil when the tree is sound.
+// Along with the checks of RTreeGN.Validate, it checks that the velocities of
+// each branch entry bound all velocities below it.
+func (tr *TPRTreeGN[N, T]) Validate() error {
+	return tr.base.Validate()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// TPRTreeG is a time-parameterized R-tree for moving objects using float64
+// coordinates and times.
+type TPRTreeG[T any] struct {
+	base TPRTreeGN[float64, T]
+}
+
+// Len returns the number of items in tree
+func (tr *TPRTreeG[T]) Len() int {
+	return tr.base.Len()
+}
+
+// Reference returns the reference time of the tree.
+func (tr *TPRTreeG[T]) Reference() float64 {
+	return tr.base.Reference()
+}
+
+// SetReference moves the reference time of the tree to t, and rebuilds the
+// tree so that its node rects are tight at that time.
+func (tr *TPRTreeG[T]) SetReference(t float64) {
+	tr.base.SetReference(t)
+}
+
+// Insert an object with the rect it has at time t, moving at velocity vel.
+func (tr *TPRTreeG[T]) Insert(min, max, vel [2]float64, t float64, data T) {
+	tr.base.Insert(min, max, vel, t, data)
+}
+
+// Delete an object. The min, max, vel, and t must be the same as the values
+// that the object was inserted with.
+func (tr *TPRTreeG[T]) Delete(min, max, vel [2]float64, t float64, data T) {
+	tr.base.Delete(min, max, vel, t, data)
+}
+
+// Replace an object.
+// If the old object does not exist then the new object is not inserted.
+func (tr *TPRTreeG[T]) Replace(
+	oldMin, oldMax, oldVel [2]float64, oldT float64, oldData T,
+	newMin, newMax, newVel [2]float64, newT float64, newData T,
+) {
+	tr.base.Replace(
+		oldMin, oldMax, oldVel, oldT, oldData,
+		newMin, newMax, newVel, newT, newData,
+	)
+}
+
+// Bounds returns the minimum bounding rect of all objects at time t.
+func (tr *TPRTreeG[T]) Bounds(t float64) (min, max [2]float64) {
+	return tr.base.Bounds(t)
+}
+
+// Scan all objects in the tree.
+// The iter function receives the rect of each object at the reference time of
+// the tree.
+func (tr *TPRTreeG[T]) Scan(
+	iter func(min, max, vel [2]float64, data T) bool,
+) {
+	tr.base.Scan(iter)
+}
+
+// SearchAt searches for objects that will intersect the provided rectangle at
+// time t.
+func (tr *TPRTreeG[T]) SearchAt(min, max [2]float64, t float64,
+	iter func(min, max [2]float64, data T) bool,
+) {
+	tr.base.SearchAt(min, max, t, iter)
+}
+
+// NearbyAt returns the objects nearest to a point at time t, ordered from the
+// smallest box distance to the largest.
+func (tr *TPRTreeG[T]) NearbyAt(point [2]float64, t float64,
+	iter func(min, max [2]float64, data T, dist float64) bool,
+) {
+	tr.base.NearbyAt(point, t, iter)
+}
+
+// Copy the tree.
+// This is a copy-on-write operation and is very fast because it only performs
+// a shadowed copy.
+func (tr *TPRTreeG[T]) Copy() *TPRTreeG[T] {
+	return &TPRTreeG[T]{*tr.base.Copy()}
+}
+
+// Clear will delete all items.
+func (tr *TPRTreeG[T]) Clear() {
+	tr.base.Clear()
+}
+
+// Validate checks the integrity of the tree and returns an error describing
+// the first problem that was found, or nil when the tree is sound.
+func (tr *TPRTreeG[T]) Validate() error {
+	return tr.base.Validate()
+}
@@ -0,0 +1,219 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+type testMover struct {
+	pos, vel [2]float64
+	t        float64
+}
+
+func (m testMover) at(t float64) [2]float64 {
+	return [2]float64{
+		m.pos[0] + m.vel[0]*(t-m.t),
+		m.pos[1] + m.vel[1]*(t-m.t),
+	}
+}
+
+func TestTPRTree(t *testing.T) {
+	rand.Seed(seed)
+	N := 10_000
+	movers := make([]testMover, N)
+	var tr TPRTreeG[int]
+	for i := range movers {
+		movers[i] = testMover{
+			pos: [2]float64{rand.Float64()*360 - 180, rand.Float64()*180 - 90},
+			vel: [2]float64{rand.Float64()*2 - 1, rand.Float64()*2 - 1},
+			t:   rand.Float64() * 10,
+		}
+		m := movers[i]
+		tr.Insert(m.pos, m.pos, m.vel, m.t, i)
+	}
+	// update half of them
+	for i := 0; i < N; i += 2 {
+		m := movers[i]
+		m2 := testMover{pos: m.at(20), vel: [2]float64{-m.vel[1], m.vel[0]},
+			t: 20}
+		tr.Replace(m.pos, m.pos, m.vel, m.t, i, m2.pos, m2.pos, m2.vel, m2.t, i)
+		movers[i] = m2
+	}
+	if tr.Len() != N {
+		t.Fatalf("expected %d, got %d", N, tr.Len())
+	}
+	if err := tr.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	testTPRTreeQueries(t, &tr, movers)
+	// move the tree to a later time, which must not change the results
+	tr.SetReference(30)
+	if tr.Reference() != 30 || tr.Len() != N {
+		t.Fatalf("expected %d items at 30, got %d at %v", N, tr.Len(),
+			tr.Reference())
+	}
+	if err := tr.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	testTPRTreeQueries(t, &tr, movers)
+	var count int
+	tr.Scan(func(min, max, vel [2]float64, i int) bool {
+		if p := movers[i].at(30); fabs(p[0]-min[0]) > 1e-9 ||
+			fabs(p[1]-min[1]) > 1e-9 || vel != movers[i].vel {
+			t.Fatalf("item %d: expected %v %v, got %v %v", i, p,
+				movers[i].vel, min, vel)
+		}
+		count++
+		return true
+	})
+	if count != N {
+		t.Fatalf("expected %d, got %d", N, count)
+	}
+	tr2 := tr.Copy()
+	for i, m := range movers[:N/2] {
+		tr.Delete(m.pos, m.pos, m.vel, m.t, i)
+	}
+	if tr.Len() != N/2 {
+		t.Fatalf("expected %d, got %d", N/2, tr.Len())
+	}
+	if err := tr.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range movers[N/2:] {
+		tr.Delete(m.pos, m.pos, m.vel, m.t, N/2+i)
+	}
+	if tr.Len() != 0 || tr.base.base.root != nil {
+		t.Fatalf("expected empty tree")
+	}
+	if err := tr.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	// the copy is not changed by the deletes
+	if tr2.Len() != N {
+		t.Fatalf("expected %d, got %d", N, tr2.Len())
+	}
+	if err := tr2.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	testTPRTreeQueries(t, tr2, movers)
+	tr2.Clear()
+	if tr2.Len() != 0 {
+		t.Fatalf("expected empty tree")
+	}
+}
+
+func testTPRTreeQueries(t *testing.T, tr *TPRTreeG[int], movers []testMover) {
+	t.Helper()
+	N := len(movers)
+	for _, at := range []float64{0, 20, 25, 50} {
+		bmin, bmax := tr.Bounds(at)
+		for i, m := range movers {
+			p := m.at(at)
+			if p[0] < bmin[0]-1e-9 || p[0] > bmax[0]+1e-9 ||
+				p[1] < bmin[1]-1e-9 || p[1] > bmax[1]+1e-9 {
+				t.Fatalf("item %d outside of bounds at %v", i, at)
+			}
+		}
+		min, max := [2]float64{-50, -30}, [2]float64{50, 30}
+		var expect int
+		for _, m := range movers {
+			p := m.at(at)
+			if p[0] >= min[0] && p[0] <= max[0] &&
+				p[1] >= min[1] && p[1] <= max[1] {
+				expect++
+			}
+		}
+		var count int
+		tr.SearchAt(min, max, at, func(min2, max2 [2]float64, i int) bool {
+			p := movers[i].at(at)
+			if p[0] < min[0] || p[0] > max[0] || p[1] < min[1] || p[1] > max[1] {
+				t.Fatalf("item %d outside of window", i)
+			}
+			count++
+			return true
+		})
+		if count != expect {
+			t.Fatalf("expected %d, got %d", expect, count)
+		}
+
+		var last float64
+		count = 0
+		tr.NearbyAt([2]float64{10, 10}, at,
+			func(min, max [2]float64, i int, dist float64) bool {
+				if dist < last {
+					t.Fatalf("out of order")
+				}
+				last = dist
+				count++
+				return true
+			},
+		)
+		if count != N {
+			t.Fatalf("expected %d, got %d", N, count)
+		}
+	}
+}
+
+func TestTPRTreeValidate(t *testing.T) {
+	rand.Seed(seed)
+	load := func() *TPRTreeGN[float64, int] {
+		var tr TPRTreeG[int]
+		for i := 0; i < 10_000; i++ {
+			pos := [2]float64{rand.Float64() * 100, rand.Float64() * 100}
+			vel := [2]float64{rand.Float64()*2 - 1, rand.Float64()*2 - 1}
+			tr.Insert(pos, pos, vel, rand.Float64()*10, i)
+		}
+		if err := tr.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		if tr.base.base.root.children()[0].leaf() {
+			t.Fatal("expected a taller tree")
+		}
+		return &tr.base
+	}
+	leaf := func(tr *TPRTreeGN[float64, int]) *node[float64, int] {
+		n := tr.base.root
+		for !n.leaf() {
+			n = n.children()[0]
+		}
+		return n
+	}
+	corruptions := []struct {
+		name string
+		fn   func(tr *TPRTreeGN[float64, int])
+	}{
+		{"count", func(tr *TPRTreeGN[float64, int]) {
+			tr.base.count--
+		}},
+		{"outside", func(tr *TPRTreeGN[float64, int]) {
+			leaf(tr).rects[0].max[0] += 1000
+		}},
+		{"faster", func(tr *TPRTreeGN[float64, int]) {
+			leaf(tr).vels[0].max[1] += 10
+		}},
+		{"slower", func(tr *TPRTreeGN[float64, int]) {
+			leaf(tr).vels[0].min[0] -= 10
+		}},
+		{"inverted", func(tr *TPRTreeGN[float64, int]) {
+			r := &leaf(tr).rects[0]
+			r.min[0], r.max[0] = r.max[0]+1, r.min[0]
+		}},
+		{"depth", func(tr *TPRTreeGN[float64, int]) {
+			tr.base.root.children()[1] = leaf(tr)
+		}},
+		{"nil child", func(tr *TPRTreeGN[float64, int]) {
+			tr.base.root.children()[1] = nil
+		}},
+	}
+	for _, c := range corruptions {
+		tr := load()
+		c.fn(tr)
+		if err := tr.Validate(); err == nil {
+			t.Fatalf("%s: expected an error", c.name)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import "errors"
+
+// Validate checks the integrity of the tree and returns an error describing
+// the first problem that was found, or nil when the tree is sound.
+//
+// It checks that all rects are valid, that each node rect is the tight bounds
+// of its entries, that all leaves are at the same depth, that entries are
+// sorted when the tree orders them, and that the item count matches Len.
+// Validate visits every node, so it's meant for tests and health checks, not
+// for hot paths.
+func (tr *RTreeGN[N, T]) Validate() error {
+	if tr.root == nil {
+		if tr.count != 0 {
+			return errors.New("nil root with non-zero count")
+		}
+		return nil
+	}
+	if tr.root.count == 0 {
+		return errors.New("empty root")
+	}
+	if err := validRect(&tr.rect); err != nil {
+		return err
+	}
+	if r := tr.root.rect(); !r.equals(&tr.rect) {
+		return errors.New("tree rect is not tight")
+	}
+	height := 0
+	for n := tr.root; !n.leaf(); n = n.children()[0] {
+		if n.count == 0 || n.children()[0] == nil {
+			return errors.New("nil child")
+		}
+		height++
+	}
+	var count int
+	if err := tr.validate(tr.root, height, &count); err != nil {
+		return err
+	}
+	if count != tr.count {
+		return errors.New("count does not match the number of items")
+	}
+	return nil
+}
+
+func (tr *RTreeGN[N, T]) validate(n *node[N, T], height int, count *int,
+) error {
+	if n.count <= 0 || int(n.count) > maxEntries {
+		return errors.New("invalid node count")
+	}
+	if n.leaf() != (height == 0) {
+		return errors.New("leaves are not at the same depth")
+	}
+	rects := n.rects[:n.count]
+	for i := range rects {
+		if err := validRect(&rects[i]); err != nil {
+			return err
+		}
+		if i > 0 && rects[i].min[0] < rects[i-1].min[0] &&
+			((n.leaf() && orderLeaves) || (!n.leaf() && orderBranches)) {
+			return errors.New("entries are not in order")
+		}
+	}
+	if n.leaf() {
+		*count += len(rects)
+		return nil
+	}
+	for i, child := range n.children()[:n.count] {
+		if child == nil {
+			return errors.New("nil child")
+		}
+		if r := child.rect(); !r.equals(&rects[i]) {
+			return errors.New("node rect is not tight")
+		}
+		if exp := child.minexp(); exp != 0 &&
+			(n.exp(i) == 0 || n.exp(i) > exp) {
+			return errors.New("expiration is not a lower bound")
+		}
+		if v, cv := n.vel(i), child.velbounds(); !v.contains(&cv) {
+			return errors.New("velocity is not bounded")
+		}
+		if err := tr.validate(child, height-1, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validRect returns an error when the rect is inverted or has a NaN.
+func validRect[N numeric](r *rect[N]) error {
+	for i := 0; i < 2; i++ {
+		if r.min[i] != r.min[i] || r.max[i] != r.max[i] {
+			return errors.New("rect has a NaN coordinate")
+		}
+		if r.min[i] > r.max[i] {
+			return errors.New("rect min is greater than max")
+		}
+	}
+	return nil
+}
+
+// Validate checks the integrity of the tree and returns an error describing
+// the first problem that was found, or nil when the tree is sound.
+func (tr *RTreeG[T]) Validate() error {
+	return tr.base.Validate()
+}
+
+// Validate checks the integrity of the tree and returns an error describing
+// the first problem that was found, or nil when the tree is sound.
+func (tr *RTree) Validate() error {
+	return tr.base.Validate()
+}
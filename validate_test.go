@@ -0,0 +1,78 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	rand.Seed(seed)
+	var empty RTree
+	if err := empty.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	pts := make([]testPoint, 10_000)
+	for i := range pts {
+		pts[i] = randTestPoint()
+	}
+	load := func() *RTreeGN[float64, testPoint] {
+		var tr RTreeG[testPoint]
+		for _, pt := range pts {
+			tr.Insert(pt.coord(), pt.coord(), pt)
+		}
+		if err := tr.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		return &tr.base
+	}
+	leaf := func(tr *RTreeGN[float64, testPoint]) *node[float64, testPoint] {
+		n := tr.root
+		for !n.leaf() {
+			n = n.children()[0]
+		}
+		return n
+	}
+	corruptions := []struct {
+		name string
+		fn   func(tr *RTreeGN[float64, testPoint])
+	}{
+		{"count", func(tr *RTreeGN[float64, testPoint]) {
+			tr.count++
+		}},
+		{"tree rect", func(tr *RTreeGN[float64, testPoint]) {
+			tr.rect.max[0]++
+		}},
+		{"loose rect", func(tr *RTreeGN[float64, testPoint]) {
+			leaf(tr).rects[0].min[0] += 0.0001
+		}},
+		{"order", func(tr *RTreeGN[float64, testPoint]) {
+			n := leaf(tr)
+			n.rects[0], n.rects[1] = n.rects[1], n.rects[0]
+		}},
+		{"nan", func(tr *RTreeGN[float64, testPoint]) {
+			leaf(tr).rects[1].min[1] = math.NaN()
+		}},
+		{"inverted", func(tr *RTreeGN[float64, testPoint]) {
+			r := &leaf(tr).rects[1]
+			r.min[1], r.max[1] = r.max[1]+1, r.min[1]
+		}},
+		{"depth", func(tr *RTreeGN[float64, testPoint]) {
+			tr.root.children()[1] = leaf(tr)
+		}},
+		{"nil child", func(tr *RTreeGN[float64, testPoint]) {
+			tr.root.children()[1] = nil
+		}},
+	}
+	for _, c := range corruptions {
+		tr := load()
+		c.fn(tr)
+		if err := tr.Validate(); err == nil {
+			t.Fatalf("%s: expected an error", c.name)
+		}
+	}
+}
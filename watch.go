@@ -0,0 +1,104 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+// WatchOp is the kind of write that triggered a WatchEvent.
+type WatchOp int8
+
+const (
+	WatchInsert WatchOp = iota
+	WatchDelete
+	WatchReplace
+)
+
+// WatchEvent describes a write that touched a watched rectangle.
+// The Old fields are set for WatchDelete and WatchReplace, and the New fields
+// are set for WatchInsert and WatchReplace.
+type WatchEvent[N numeric, T any] struct {
+	Op      WatchOp
+	OldMin  [2]N
+	OldMax  [2]N
+	OldData T
+	NewMin  [2]N
+	NewMax  [2]N
+	NewData T
+}
+
+// watchers holds all subscriptions for a tree. The subscription rects are
+// stored in their own R-tree so that each write only visits the watchers that
+// it intersects.
+type watchers[N numeric, T any] struct {
+	seq uint64
+	tr  RTreeGN[N, uint64]
+	fns map[uint64]func(ev WatchEvent[N, T])
+}
+
+// Watch subscribes to all writes that intersect the provided rectangle.
+// The fn function is called after each Insert, Delete, or Replace where the
+// item rect intersects the watched rect. For a Replace, the watcher is called
+// once when either the old rect or the new rect intersects.
+// Calling the returned cancel function removes the subscription.
+//
+// Watchers belong to the tree that they were added to and are not carried
+// over to trees created with Copy.
+func (tr *RTreeGN[N, T]) Watch(min, max [2]N, fn func(ev WatchEvent[N, T]),
+) (cancel func()) {
+	if tr.watch == nil {
+		tr.watch = &watchers[N, T]{fns: make(map[uint64]func(ev WatchEvent[N, T]))}
+	}
+	w := tr.watch
+	w.seq++
+	id := w.seq
+	w.fns[id] = fn
+	w.tr.Insert(min, max, id)
+	return func() {
+		if _, ok := w.fns[id]; ok {
+			delete(w.fns, id)
+			w.tr.Delete(min, max, id)
+		}
+	}
+}
+
+func (w *watchers[N, T]) notify(ev WatchEvent[N, T]) {
+	if len(w.fns) == 0 {
+		return
+	}
+	var ids []uint64
+	if ev.Op != WatchInsert {
+		w.tr.Search(ev.OldMin, ev.OldMax, func(_, _ [2]N, id uint64) bool {
+			ids = append(ids, id)
+			return true
+		})
+	}
+	if ev.Op != WatchDelete {
+		nold := len(ids)
+		w.tr.Search(ev.NewMin, ev.NewMax, func(_, _ [2]N, id uint64) bool {
+			for i := 0; i < nold; i++ {
+				if ids[i] == id {
+					return true
+				}
+			}
+			ids = append(ids, id)
+			return true
+		})
+	}
+	for _, id := range ids {
+		// The watcher may have been canceled by an earlier callback.
+		if fn, ok := w.fns[id]; ok {
+			fn(ev)
+		}
+	}
+}
+
+// Watch subscribes to all writes that intersect the provided rectangle.
+// The fn function is called after each Insert, Delete, or Replace where the
+// item rect intersects the watched rect. For a Replace, the watcher is called
+// once when either the old rect or the new rect intersects.
+// Calling the returned cancel function removes the subscription.
+func (tr *RTreeG[T]) Watch(min, max [2]float64,
+	fn func(ev WatchEvent[float64, T]),
+) (cancel func()) {
+	return tr.base.Watch(min, max, fn)
+}
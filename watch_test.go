@@ -0,0 +1,67 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWatch(t *testing.T) {
+	var tr RTreeG[int]
+	var left, right []string
+	cancelLeft := tr.Watch([2]float64{0, 0}, [2]float64{10, 10},
+		func(ev WatchEvent[float64, int]) {
+			left = append(left, fmt.Sprintf("%d:%d:%d", ev.Op, ev.OldData,
+				ev.NewData))
+		},
+	)
+	tr.Watch([2]float64{20, 0}, [2]float64{30, 10},
+		func(ev WatchEvent[float64, int]) {
+			right = append(right, fmt.Sprintf("%d:%d:%d", ev.Op, ev.OldData,
+				ev.NewData))
+		},
+	)
+	tr.Insert([2]float64{5, 5}, [2]float64{5, 5}, 1)
+	tr.Insert([2]float64{15, 5}, [2]float64{15, 5}, 2)
+	tr.Insert([2]float64{25, 5}, [2]float64{25, 5}, 3)
+	// move 1 from left to right
+	tr.Replace(
+		[2]float64{5, 5}, [2]float64{5, 5}, 1,
+		[2]float64{25, 6}, [2]float64{25, 6}, 1,
+	)
+	// missing item, no events
+	tr.Delete([2]float64{5, 5}, [2]float64{5, 5}, 1)
+	tr.Delete([2]float64{25, 5}, [2]float64{25, 5}, 3)
+	cancelLeft()
+	cancelLeft()
+	tr.Insert([2]float64{5, 5}, [2]float64{5, 5}, 4)
+	if exp := "[0:0:1 2:1:1]"; fmt.Sprint(left) != exp {
+		t.Fatalf("expected %s, got %s", exp, left)
+	}
+	if exp := "[0:0:3 2:1:1 1:3:0]"; fmt.Sprint(right) != exp {
+		t.Fatalf("expected %s, got %s", exp, right)
+	}
+	if tr.Copy().base.watch != nil {
+		t.Fatal("expected no watchers on copy")
+	}
+
+	// a delete with a larger rect reports the rect of the removed item, and
+	// only to the watchers of that rect
+	var tr2 RTreeG[int]
+	var evs []WatchEvent[float64, int]
+	var far int
+	tr2.Watch([2]float64{0, 0}, [2]float64{10, 10},
+		func(ev WatchEvent[float64, int]) { evs = append(evs, ev) })
+	tr2.Watch([2]float64{50, 50}, [2]float64{60, 60},
+		func(ev WatchEvent[float64, int]) { far++ })
+	tr2.Insert([2]float64{5, 5}, [2]float64{6, 6}, 1)
+	tr2.Insert([2]float64{90, 90}, [2]float64{90, 90}, 2)
+	tr2.Delete([2]float64{5, 5}, [2]float64{80, 80}, 1)
+	if len(evs) != 2 || far != 0 || evs[1].Op != WatchDelete ||
+		evs[1].OldMin != [2]float64{5, 5} || evs[1].OldMax != [2]float64{6, 6} {
+		t.Fatalf("unexpected events %v, %d", evs, far)
+	}
+}